@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func (d *DeribitRestClient) GetAnnouncements() (result []models.Announcement, err error) {
+	return d.GetAnnouncementsContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetAnnouncementsContext(ctx context.Context) (result []models.Announcement, err error) {
+	err = d.CallContext(ctx, "public/get_announcements", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) ChangeSubaccountName(params *models.ChangeSubaccountNameParams) (result string, err error) {
+	return d.ChangeSubaccountNameContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) ChangeSubaccountNameContext(ctx context.Context, params *models.ChangeSubaccountNameParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/change_subaccount_name", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CreateSubaccount() (result models.Subaccount, err error) {
+	return d.CreateSubaccountContext(context.Background())
+}
+
+func (d *DeribitRestClient) CreateSubaccountContext(ctx context.Context) (result models.Subaccount, err error) {
+	err = d.CallContext(ctx, "private/create_subaccount", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) DisableTfaForSubaccount(params *models.DisableTfaForSubaccountParams) (result string, err error) {
+	return d.DisableTfaForSubaccountContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) DisableTfaForSubaccountContext(ctx context.Context, params *models.DisableTfaForSubaccountParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/disable_tfa_for_subaccount", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetAccountSummary(params *models.GetAccountSummaryParams) (result models.AccountSummary, err error) {
+	return d.GetAccountSummaryContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetAccountSummaryContext(ctx context.Context, params *models.GetAccountSummaryParams) (result models.AccountSummary, err error) {
+	err = d.CallContext(ctx, "private/get_account_summary", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetEmailLanguage() (result string, err error) {
+	return d.GetEmailLanguageContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetEmailLanguageContext(ctx context.Context) (result string, err error) {
+	err = d.CallContext(ctx, "private/get_email_language", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetNewAnnouncements() (result []models.Announcement, err error) {
+	return d.GetNewAnnouncementsContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetNewAnnouncementsContext(ctx context.Context) (result []models.Announcement, err error) {
+	err = d.CallContext(ctx, "private/get_new_announcements", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetPosition(params *models.GetPositionParams) (result models.Position, err error) {
+	return d.GetPositionContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetPositionContext(ctx context.Context, params *models.GetPositionParams) (result models.Position, err error) {
+	err = d.CallContext(ctx, "private/get_position", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetPositions(params *models.GetPositionsParams) (result []models.Position, err error) {
+	return d.GetPositionsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetPositionsContext(ctx context.Context, params *models.GetPositionsParams) (result []models.Position, err error) {
+	err = d.CallContext(ctx, "private/get_positions", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetSubaccounts(params *models.GetSubaccountsParams) (result []models.Subaccount, err error) {
+	return d.GetSubaccountsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetSubaccountsContext(ctx context.Context, params *models.GetSubaccountsParams) (result []models.Subaccount, err error) {
+	err = d.CallContext(ctx, "private/get_subaccounts", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetSubaccountsDetails(params *models.GetSubaccountsDetailsParams) (result []models.SubaccountsDetails, err error) {
+	return d.GetSubaccountsDetailsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetSubaccountsDetailsContext(ctx context.Context, params *models.GetSubaccountsDetailsParams) (result []models.SubaccountsDetails, err error) {
+	err = d.CallContext(ctx, "private/get_subaccounts_details", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) SetAnnouncementAsRead(params *models.SetAnnouncementAsReadParams) (result string, err error) {
+	return d.SetAnnouncementAsReadContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) SetAnnouncementAsReadContext(ctx context.Context, params *models.SetAnnouncementAsReadParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/set_announcement_as_read", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) SetEmailForSubaccount(params *models.SetEmailForSubaccountParams) (result string, err error) {
+	return d.SetEmailForSubaccountContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) SetEmailForSubaccountContext(ctx context.Context, params *models.SetEmailForSubaccountParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/set_email_for_subaccount", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) SetEmailLanguage(params *models.SetEmailLanguageParams) (result string, err error) {
+	return d.SetEmailLanguageContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) SetEmailLanguageContext(ctx context.Context, params *models.SetEmailLanguageParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/set_email_language", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) SetPasswordForSubaccount(params *models.SetPasswordForSubaccountParams) (result string, err error) {
+	return d.SetPasswordForSubaccountContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) SetPasswordForSubaccountContext(ctx context.Context, params *models.SetPasswordForSubaccountParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/set_password_for_subaccount", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) ToggleNotificationsFromSubaccount(params *models.ToggleNotificationsFromSubaccountParams) (result string, err error) {
+	return d.ToggleNotificationsFromSubaccountContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) ToggleNotificationsFromSubaccountContext(ctx context.Context, params *models.ToggleNotificationsFromSubaccountParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/toggle_notifications_from_subaccount", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) ToggleSubaccountLogin(params *models.ToggleSubaccountLoginParams) (result string, err error) {
+	return d.ToggleSubaccountLoginContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) ToggleSubaccountLoginContext(ctx context.Context, params *models.ToggleSubaccountLoginParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/toggle_subaccount_login", params, &result)
+	return
+}
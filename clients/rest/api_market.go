@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func (d *DeribitRestClient) GetBookSummaryByCurrency(params *models.GetBookSummaryByCurrencyParams) (result []models.BookSummary, err error) {
+	return d.GetBookSummaryByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetBookSummaryByCurrencyContext(ctx context.Context, params *models.GetBookSummaryByCurrencyParams) (result []models.BookSummary, err error) {
+	err = d.CallContext(ctx, "public/get_book_summary_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetBookSummaryByInstrument(params *models.GetBookSummaryByInstrumentParams) (result []models.BookSummary, err error) {
+	return d.GetBookSummaryByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetBookSummaryByInstrumentContext(ctx context.Context, params *models.GetBookSummaryByInstrumentParams) (result []models.BookSummary, err error) {
+	err = d.CallContext(ctx, "public/get_book_summary_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetContractSize(params *models.GetContractSizeParams) (result models.GetContractSizeResponse, err error) {
+	return d.GetContractSizeContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetContractSizeContext(ctx context.Context, params *models.GetContractSizeParams) (result models.GetContractSizeResponse, err error) {
+	err = d.CallContext(ctx, "public/get_contract_size", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetCurrencies() (result []models.Currency, err error) {
+	return d.GetCurrenciesContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetCurrenciesContext(ctx context.Context) (result []models.Currency, err error) {
+	err = d.CallContext(ctx, "public/get_currencies", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetFundingChartData(params *models.GetFundingChartDataParams) (result models.GetFundingChartDataResponse, err error) {
+	return d.GetFundingChartDataContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetFundingChartDataContext(ctx context.Context, params *models.GetFundingChartDataParams) (result models.GetFundingChartDataResponse, err error) {
+	err = d.CallContext(ctx, "public/get_funding_chart_data", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetFundingRateValue(params *models.GetFundingRateValueParams) (result float64, err error) {
+	return d.GetFundingRateValueContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetFundingRateValueContext(ctx context.Context, params *models.GetFundingRateValueParams) (result float64, err error) {
+	err = d.CallContext(ctx, "public/get_funding_rate_value", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetHistoricalVolatility(params *models.GetHistoricalVolatilityParams) (result models.GetHistoricalVolatilityResponse, err error) {
+	return d.GetHistoricalVolatilityContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetHistoricalVolatilityContext(ctx context.Context, params *models.GetHistoricalVolatilityParams) (result models.GetHistoricalVolatilityResponse, err error) {
+	err = d.CallContext(ctx, "public/get_historical_volatility", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetIndex(params *models.GetIndexParams) (result models.GetIndexResponse, err error) {
+	return d.GetIndexContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetIndexContext(ctx context.Context, params *models.GetIndexParams) (result models.GetIndexResponse, err error) {
+	err = d.CallContext(ctx, "public/get_index", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetInstruments(params *models.GetInstrumentsParams) (result []models.Instrument, err error) {
+	return d.GetInstrumentsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetInstrumentsContext(ctx context.Context, params *models.GetInstrumentsParams) (result []models.Instrument, err error) {
+	err = d.CallContext(ctx, "public/get_instruments", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetInstrument(params *models.GetInstrumentParams) (result models.Instrument, err error) {
+	return d.GetInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetInstrumentContext(ctx context.Context, params *models.GetInstrumentParams) (result models.Instrument, err error) {
+	err = d.CallContext(ctx, "public/get_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastSettlementsByCurrency(params *models.GetLastSettlementsByCurrencyParams) (result models.GetLastSettlementsResponse, err error) {
+	return d.GetLastSettlementsByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastSettlementsByCurrencyContext(ctx context.Context, params *models.GetLastSettlementsByCurrencyParams) (result models.GetLastSettlementsResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_settlements_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastSettlementsByInstrument(params *models.GetLastSettlementsByInstrumentParams) (result models.GetLastSettlementsResponse, err error) {
+	return d.GetLastSettlementsByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastSettlementsByInstrumentContext(ctx context.Context, params *models.GetLastSettlementsByInstrumentParams) (result models.GetLastSettlementsResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_settlements_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastTradesByCurrency(params *models.GetLastTradesByCurrencyParams) (result models.GetLastTradesResponse, err error) {
+	return d.GetLastTradesByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastTradesByCurrencyContext(ctx context.Context, params *models.GetLastTradesByCurrencyParams) (result models.GetLastTradesResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_trades_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastTradesByCurrencyAndTime(params *models.GetLastTradesByCurrencyAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	return d.GetLastTradesByCurrencyAndTimeContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastTradesByCurrencyAndTimeContext(ctx context.Context, params *models.GetLastTradesByCurrencyAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_trades_by_currency_and_time", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastTradesByInstrument(params *models.GetLastTradesByInstrumentParams) (result models.GetLastTradesResponse, err error) {
+	return d.GetLastTradesByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastTradesByInstrumentContext(ctx context.Context, params *models.GetLastTradesByInstrumentParams) (result models.GetLastTradesResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_trades_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetLastTradesByInstrumentAndTime(params *models.GetLastTradesByInstrumentAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	return d.GetLastTradesByInstrumentAndTimeContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetLastTradesByInstrumentAndTimeContext(ctx context.Context, params *models.GetLastTradesByInstrumentAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	err = d.CallContext(ctx, "public/get_last_trades_by_instrument_and_time", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOrderBook(params *models.GetOrderBookParams) (result models.GetOrderBookResponse, err error) {
+	return d.GetOrderBookContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOrderBookContext(ctx context.Context, params *models.GetOrderBookParams) (result models.GetOrderBookResponse, err error) {
+	err = d.CallContext(ctx, "public/get_order_book", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetTradeVolumes() (result models.GetTradeVolumesResponse, err error) {
+	return d.GetTradeVolumesContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetTradeVolumesContext(ctx context.Context) (result models.GetTradeVolumesResponse, err error) {
+	err = d.CallContext(ctx, "public/get_trade_volumes", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetTradingviewChartData(params *models.GetTradingviewChartDataParams) (result models.GetTradingviewChartDataResponse, err error) {
+	return d.GetTradingviewChartDataContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetTradingviewChartDataContext(ctx context.Context, params *models.GetTradingviewChartDataParams) (result models.GetTradingviewChartDataResponse, err error) {
+	err = d.CallContext(ctx, "public/get_tradingview_chart_data", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) Ticker(params *models.TickerParams) (result models.TickerResponse, err error) {
+	return d.TickerContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) TickerContext(ctx context.Context, params *models.TickerParams) (result models.TickerResponse, err error) {
+	err = d.CallContext(ctx, "public/ticker", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetMarkPriceHistory(params *models.GetMarkPriceHistoryParams) (resut models.MarkPriceHistory, err error) {
+	return d.GetMarkPriceHistoryContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetMarkPriceHistoryContext(ctx context.Context, params *models.GetMarkPriceHistoryParams) (resut models.MarkPriceHistory, err error) {
+	err = d.CallContext(ctx, "public/get_mark_price_history", params, &resut)
+	return
+}
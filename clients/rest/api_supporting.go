@@ -0,0 +1,25 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func (d *DeribitRestClient) GetTime() (result int64, err error) {
+	return d.GetTimeContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetTimeContext(ctx context.Context) (result int64, err error) {
+	err = d.CallContext(ctx, "public/get_time", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) Test() (result models.TestResponse, err error) {
+	return d.TestContext(context.Background())
+}
+
+func (d *DeribitRestClient) TestContext(ctx context.Context) (result models.TestResponse, err error) {
+	err = d.CallContext(ctx, "public/test", nil, &result)
+	return
+}
@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/clients/websocket"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestBehavior(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch r.URL.Path {
+		case "/public/get_order_book":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"instrument_name":"BTC-PERPETUAL","best_bid_price":9000.5}}`))
+		case "/private/buy":
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":2,"result":{"order":{"order_id":"1","direction":"buy"},"trades":[]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
+	}
+	client.SetAccessToken("test-token")
+	var api websocket.Behavior = client
+
+	book, err := api.GetOrderBook(&models.GetOrderBookParams{InstrumentName: "BTC-PERPETUAL", Depth: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC-PERPETUAL", book.InstrumentName)
+	assert.Equal(t, 9000.5, book.BestBidPrice)
+
+	buy, err := api.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: models.OrderTypeMarket})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", buy.Order.OrderID)
+
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, "5", requests[0].URL.Query().Get("depth"))
+		assert.Empty(t, requests[0].Header.Get("Authorization"))
+		assert.Equal(t, "10", requests[1].URL.Query().Get("amount"))
+		assert.Equal(t, "market", requests[1].URL.Query().Get("type"))
+		assert.Equal(t, "Bearer test-token", requests[1].Header.Get("Authorization"))
+	}
+}
@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"context"
+
+	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func (d *DeribitRestClient) Buy(params *models.BuyParams) (result models.BuyResponse, err error) {
+	return d.BuyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) BuyContext(ctx context.Context, params *models.BuyParams) (result models.BuyResponse, err error) {
+	err = d.CallContext(ctx, "private/buy", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) Sell(params *models.SellParams) (result models.SellResponse, err error) {
+	return d.SellContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) SellContext(ctx context.Context, params *models.SellParams) (result models.SellResponse, err error) {
+	err = d.CallContext(ctx, "private/sell", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) Edit(params *models.EditParams) (result models.EditResponse, err error) {
+	return d.EditContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) EditContext(ctx context.Context, params *models.EditParams) (result models.EditResponse, err error) {
+	err = d.CallContext(ctx, "private/edit", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) Cancel(params *models.CancelParams) (result models2.Order, err error) {
+	return d.CancelContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelContext(ctx context.Context, params *models.CancelParams) (result models2.Order, err error) {
+	err = d.CallContext(ctx, "private/cancel", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CancelAll() (result string, err error) {
+	return d.CancelAllContext(context.Background())
+}
+
+func (d *DeribitRestClient) CancelAllContext(ctx context.Context) (result string, err error) {
+	err = d.CallContext(ctx, "private/cancel_all", nil, &result)
+	return
+}
+
+func (d *DeribitRestClient) CancelAllByCurrency(params *models.CancelAllByCurrencyParams) (result string, err error) {
+	return d.CancelAllByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelAllByCurrencyContext(ctx context.Context, params *models.CancelAllByCurrencyParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/cancel_all_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CancelAllByInstrument(params *models.CancelAllByInstrumentParams) (result string, err error) {
+	return d.CancelAllByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelAllByInstrumentContext(ctx context.Context, params *models.CancelAllByInstrumentParams) (result string, err error) {
+	err = d.CallContext(ctx, "private/cancel_all_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CancelByLabel(params *models.CancelByLabelParams) (result int, err error) {
+	return d.CancelByLabelContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelByLabelContext(ctx context.Context, params *models.CancelByLabelParams) (result int, err error) {
+	err = d.CallContext(ctx, "private/cancel_by_label", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) ClosePosition(params *models.ClosePositionParams) (result models.ClosePositionResponse, err error) {
+	return d.ClosePositionContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) ClosePositionContext(ctx context.Context, params *models.ClosePositionParams) (result models.ClosePositionResponse, err error) {
+	err = d.CallContext(ctx, "private/close_position", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetMargins(params *models.GetMarginsParams) (result models.GetMarginsResponse, err error) {
+	return d.GetMarginsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetMarginsContext(ctx context.Context, params *models.GetMarginsParams) (result models.GetMarginsResponse, err error) {
+	err = d.CallContext(ctx, "private/get_margins", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOpenOrdersByCurrency(params *models.GetOpenOrdersByCurrencyParams) (result []models2.Order, err error) {
+	return d.GetOpenOrdersByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOpenOrdersByCurrencyContext(ctx context.Context, params *models.GetOpenOrdersByCurrencyParams) (result []models2.Order, err error) {
+	err = d.CallContext(ctx, "private/get_open_orders_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOpenOrdersByInstrument(params *models.GetOpenOrdersByInstrumentParams) (result []models2.Order, err error) {
+	return d.GetOpenOrdersByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOpenOrdersByInstrumentContext(ctx context.Context, params *models.GetOpenOrdersByInstrumentParams) (result []models2.Order, err error) {
+	err = d.CallContext(ctx, "private/get_open_orders_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOrderHistoryByCurrency(params *models.GetOrderHistoryByCurrencyParams) (result []models2.Order, err error) {
+	return d.GetOrderHistoryByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOrderHistoryByCurrencyContext(ctx context.Context, params *models.GetOrderHistoryByCurrencyParams) (result []models2.Order, err error) {
+	err = d.CallContext(ctx, "private/get_order_history_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOrderHistoryByInstrument(params *models.GetOrderHistoryByInstrumentParams) (result []models2.Order, err error) {
+	return d.GetOrderHistoryByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOrderHistoryByInstrumentContext(ctx context.Context, params *models.GetOrderHistoryByInstrumentParams) (result []models2.Order, err error) {
+	err = d.CallContext(ctx, "private/get_order_history_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOrderMarginByIDs(params *models.GetOrderMarginByIDsParams) (result models.GetOrderMarginByIDsResponse, err error) {
+	return d.GetOrderMarginByIDsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOrderMarginByIDsContext(ctx context.Context, params *models.GetOrderMarginByIDsParams) (result models.GetOrderMarginByIDsResponse, err error) {
+	err = d.CallContext(ctx, "private/get_order_margin_by_ids", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetOrderState(params *models.GetOrderStateParams) (result models2.Order, err error) {
+	return d.GetOrderStateContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetOrderStateContext(ctx context.Context, params *models.GetOrderStateParams) (result models2.Order, err error) {
+	err = d.CallContext(ctx, "private/get_order_state", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetStopOrderHistory(params *models.GetStopOrderHistoryParams) (result models.GetStopOrderHistoryResponse, err error) {
+	return d.GetStopOrderHistoryContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetStopOrderHistoryContext(ctx context.Context, params *models.GetStopOrderHistoryParams) (result models.GetStopOrderHistoryResponse, err error) {
+	err = d.CallContext(ctx, "private/get_stop_order_history", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetUserTradesByCurrency(params *models.GetUserTradesByCurrencyParams) (result models.GetUserTradesResponse, err error) {
+	return d.GetUserTradesByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetUserTradesByCurrencyContext(ctx context.Context, params *models.GetUserTradesByCurrencyParams) (result models.GetUserTradesResponse, err error) {
+	err = d.CallContext(ctx, "private/get_user_trades_by_currency", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetUserTradesByCurrencyAndTime(params *models.GetUserTradesByCurrencyAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	return d.GetUserTradesByCurrencyAndTimeContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetUserTradesByCurrencyAndTimeContext(ctx context.Context, params *models.GetUserTradesByCurrencyAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	err = d.CallContext(ctx, "private/get_user_trades_by_currency_and_time", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetUserTradesByInstrument(params *models.GetUserTradesByInstrumentParams) (result models.GetUserTradesResponse, err error) {
+	return d.GetUserTradesByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetUserTradesByInstrumentContext(ctx context.Context, params *models.GetUserTradesByInstrumentParams) (result models.GetUserTradesResponse, err error) {
+	err = d.CallContext(ctx, "private/get_user_trades_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetUserTradesByInstrumentAndTime(params *models.GetUserTradesByInstrumentAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	return d.GetUserTradesByInstrumentAndTimeContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetUserTradesByInstrumentAndTimeContext(ctx context.Context, params *models.GetUserTradesByInstrumentAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	err = d.CallContext(ctx, "private/get_user_trades_by_instrument_and_time", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetUserTradesByOrder(params *models.GetUserTradesByOrderParams) (result models.GetUserTradesResponse, err error) {
+	return d.GetUserTradesByOrderContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetUserTradesByOrderContext(ctx context.Context, params *models.GetUserTradesByOrderParams) (result models.GetUserTradesResponse, err error) {
+	err = d.CallContext(ctx, "private/get_user_trades_by_order", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetSettlementHistoryByInstrument(params *models.GetSettlementHistoryByInstrumentParams) (result models.GetSettlementHistoryResponse, err error) {
+	return d.GetSettlementHistoryByInstrumentContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetSettlementHistoryByInstrumentContext(ctx context.Context, params *models.GetSettlementHistoryByInstrumentParams) (result models.GetSettlementHistoryResponse, err error) {
+	err = d.CallContext(ctx, "private/get_settlement_history_by_instrument", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetSettlementHistoryByCurrency(params *models.GetSettlementHistoryByCurrencyParams) (result models.GetSettlementHistoryResponse, err error) {
+	return d.GetSettlementHistoryByCurrencyContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetSettlementHistoryByCurrencyContext(ctx context.Context, params *models.GetSettlementHistoryByCurrencyParams) (result models.GetSettlementHistoryResponse, err error) {
+	err = d.CallContext(ctx, "private/get_settlement_history_by_currency", params, &result)
+	return
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func (d *DeribitRestClient) CancelTransferByID(params *models.CancelTransferByIDParams) (result models.Transfer, err error) {
+	return d.CancelTransferByIDContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelTransferByIDContext(ctx context.Context, params *models.CancelTransferByIDParams) (result models.Transfer, err error) {
+	err = d.CallContext(ctx, "private/cancel_transfer_by_id", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CancelWithdrawal(params *models.CancelWithdrawalParams) (result models.Withdrawal, err error) {
+	return d.CancelWithdrawalContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CancelWithdrawalContext(ctx context.Context, params *models.CancelWithdrawalParams) (result models.Withdrawal, err error) {
+	err = d.CallContext(ctx, "private/cancel_withdrawal", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) CreateDepositAddress(params *models.CreateDepositAddressParams) (result models.DepositAddress, err error) {
+	return d.CreateDepositAddressContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) CreateDepositAddressContext(ctx context.Context, params *models.CreateDepositAddressParams) (result models.DepositAddress, err error) {
+	err = d.CallContext(ctx, "private/create_deposit_address", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetCurrentDepositAddress(params *models.GetCurrentDepositAddressParams) (result models.DepositAddress, err error) {
+	return d.GetCurrentDepositAddressContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetCurrentDepositAddressContext(ctx context.Context, params *models.GetCurrentDepositAddressParams) (result models.DepositAddress, err error) {
+	err = d.CallContext(ctx, "private/get_current_deposit_address", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetDeposits(params *models.GetDepositsParams) (result models.GetDepositsResponse, err error) {
+	return d.GetDepositsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetDepositsContext(ctx context.Context, params *models.GetDepositsParams) (result models.GetDepositsResponse, err error) {
+	err = d.CallContext(ctx, "private/get_deposits", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetTransfers(params *models.GetTransfersParams) (result models.GetTransfersResponse, err error) {
+	return d.GetTransfersContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetTransfersContext(ctx context.Context, params *models.GetTransfersParams) (result models.GetTransfersResponse, err error) {
+	err = d.CallContext(ctx, "private/get_transfers", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) GetWithdrawals(params *models.GetWithdrawalsParams) (result []models.Withdrawal, err error) {
+	return d.GetWithdrawalsContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) GetWithdrawalsContext(ctx context.Context, params *models.GetWithdrawalsParams) (result []models.Withdrawal, err error) {
+	err = d.CallContext(ctx, "private/get_withdrawals", params, &result)
+	return
+}
+
+func (d *DeribitRestClient) Withdraw(params *models.WithdrawParams) (result models.Withdrawal, err error) {
+	return d.WithdrawContext(context.Background(), params)
+}
+
+func (d *DeribitRestClient) WithdrawContext(ctx context.Context, params *models.WithdrawParams) (result models.Withdrawal, err error) {
+	err = d.CallContext(ctx, "private/withdraw", params, &result)
+	return
+}
@@ -1,5 +1,18 @@
 package rest
 
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// refreshAt is the fraction of the token lifetime after which the client
+// refreshes it.
+const refreshAt = 0.8
+
 type AuthResponse struct {
 	RefreshToken string `json:"refresh_token"`
 	AccessToken  string `json:"access_token"`
@@ -7,3 +20,207 @@ type AuthResponse struct {
 	Scope        string `json:"scope"`
 	TokenType    string `json:"token_type"`
 }
+
+// authState is the current token and when it expires.
+type authState struct {
+	token     string
+	refresh   string
+	issuedAt  time.Time
+	expiresAt time.Time
+}
+
+// AccessToken returns the current access token, or "" before the client has
+// authenticated.
+func (d *DeribitRestClient) AccessToken() string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.auth.token
+}
+
+// SetAccessToken makes the client use token, obtained elsewhere, for private
+// calls. The token is not refreshed; once the server rejects it the client
+// authenticates with its own credentials.
+func (d *DeribitRestClient) SetAccessToken(token string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.auth = authState{token: token, issuedAt: time.Now()}
+}
+
+// TokenExpiresAt returns when the current access token expires, or the zero
+// time when the client is not authenticated or the expiry is unknown.
+func (d *DeribitRestClient) TokenExpiresAt() time.Time {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.auth.expiresAt
+}
+
+func (d *DeribitRestClient) setAuth(result AuthResponse) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.auth = authState{
+		token:    result.AccessToken,
+		refresh:  result.RefreshToken,
+		issuedAt: time.Now(),
+	}
+	if result.ExpiresIn > 0 {
+		d.auth.expiresAt = d.auth.issuedAt.Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+}
+
+// GetAuthToken authenticates with the client credentials and returns the new
+// access token. With SignatureAuth set it uses the client_signature grant.
+func (d *DeribitRestClient) GetAuthToken() (string, error) {
+	return d.GetAuthTokenContext(context.Background())
+}
+
+func (d *DeribitRestClient) GetAuthTokenContext(ctx context.Context) (string, error) {
+	d.authMu.Lock()
+	defer d.authMu.Unlock()
+
+	if err := d.authenticate(ctx); err != nil {
+		return "", err
+	}
+	return d.AccessToken(), nil
+}
+
+// RefreshToken exchanges the stored refresh token for a new access token.
+func (d *DeribitRestClient) RefreshToken() error {
+	return d.RefreshTokenContext(context.Background())
+}
+
+func (d *DeribitRestClient) RefreshTokenContext(ctx context.Context) error {
+	d.authMu.Lock()
+	defer d.authMu.Unlock()
+
+	return d.refreshToken(ctx)
+}
+
+// authenticate runs public/auth with the client credentials. Callers hold
+// d.authMu.
+func (d *DeribitRestClient) authenticate(ctx context.Context) error {
+	var params interface{} = &models.ClientCredentialsParams{
+		GrantType:    "client_credentials",
+		ClientID:     d.ClientID,
+		ClientSecret: d.ApiSecret,
+	}
+	if d.SignatureAuth {
+		timestamp := time.Now().UnixMilli()
+		nonce := deribit.Nonce()
+		params = &models.ClientSignatureParams{
+			GrantType: "client_signature",
+			ClientID:  d.ClientID,
+			Timestamp: timestamp,
+			Signature: deribit.Signature(d.ApiSecret, timestamp, nonce, ""),
+			Nonce:     nonce,
+		}
+	}
+	result, err := Call[AuthResponse](ctx, d, "public/auth", params)
+	if err != nil {
+		return err
+	}
+	d.setAuth(result)
+	return nil
+}
+
+// refreshToken runs public/auth with the refresh_token grant. Callers hold
+// d.authMu.
+func (d *DeribitRestClient) refreshToken(ctx context.Context) error {
+	d.mu.RLock()
+	refresh := d.auth.refresh
+	d.mu.RUnlock()
+	if refresh == "" {
+		return errors.New("no refresh token")
+	}
+	result, err := Call[AuthResponse](ctx, d, "public/auth", &models.RefreshTokenParams{
+		GrantType:    "refresh_token",
+		RefreshToken: refresh,
+	})
+	if err != nil {
+		return err
+	}
+	d.setAuth(result)
+	return nil
+}
+
+func (d *DeribitRestClient) hasCredentials() bool {
+	return d.ClientID != "" && d.ApiSecret != ""
+}
+
+// validToken returns a token for a private call. It authenticates on first
+// use and refreshes the token once most of its lifetime has passed, falling
+// back to the client credentials if the refresh fails. Concurrent callers
+// wait for a single round trip.
+func (d *DeribitRestClient) validToken(ctx context.Context) (string, error) {
+	if token, ok := d.freshToken(); ok {
+		return token, nil
+	}
+
+	d.authMu.Lock()
+	defer d.authMu.Unlock()
+
+	// Another caller may have renewed the token while we waited.
+	if token, ok := d.freshToken(); ok {
+		return token, nil
+	}
+
+	d.mu.RLock()
+	state := d.auth
+	d.mu.RUnlock()
+
+	if state.token != "" && state.refresh != "" {
+		err := d.refreshToken(ctx)
+		if err == nil {
+			return d.AccessToken(), nil
+		}
+		d.Logger.Warnf("token refresh failed: %v", err)
+	}
+	if !d.hasCredentials() {
+		// Keep using a token set with SetAccessToken; without one the
+		// server answers with an authorization error.
+		return state.token, nil
+	}
+	if err := d.authenticate(ctx); err != nil {
+		return "", err
+	}
+	return d.AccessToken(), nil
+}
+
+// freshToken returns the current token if it does not need renewing yet.
+func (d *DeribitRestClient) freshToken() (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.auth.token == "" {
+		return "", false
+	}
+	if d.auth.expiresAt.IsZero() {
+		return d.auth.token, true
+	}
+	lifetime := d.auth.expiresAt.Sub(d.auth.issuedAt)
+	return d.auth.token, time.Now().Before(d.auth.issuedAt.Add(time.Duration(float64(lifetime) * refreshAt)))
+}
+
+// reauthenticate replaces a token the server rejected. If another caller has
+// already replaced it, the new token is returned without a round trip.
+func (d *DeribitRestClient) reauthenticate(ctx context.Context, rejected string) (string, error) {
+	d.authMu.Lock()
+	defer d.authMu.Unlock()
+
+	if token := d.AccessToken(); token != rejected && token != "" {
+		return token, nil
+	}
+	if err := d.authenticate(ctx); err != nil {
+		return "", err
+	}
+	return d.AccessToken(), nil
+}
+
+// isAuthError reports whether err means the request's credentials were
+// rejected.
+func isAuthError(err error) bool {
+	return errors.Is(err, deribit.ErrInvalidToken) || errors.Is(err, deribit.ErrInvalidCredentials)
+}
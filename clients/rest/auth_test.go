@@ -0,0 +1,145 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// authServer issues numbered tokens and accepts private calls carrying the
+// latest one.
+type authServer struct {
+	mu        sync.Mutex
+	issued    int
+	valid     string
+	grants    []string
+	rejectAll bool
+}
+
+func (s *authServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/public/auth" {
+		s.grants = append(s.grants, r.URL.Query().Get("grant_type"))
+		s.issued++
+		s.valid = fmt.Sprintf("token-%d", s.issued)
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"access_token":%q,"refresh_token":"refresh-%d","expires_in":900}}`, s.valid, s.issued)
+		return
+	}
+	if s.rejectAll || r.Header.Get("Authorization") != "Bearer "+s.valid {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":13009,"message":"unauthorized"}}`))
+		return
+	}
+	_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"currency":"BTC","equity":1.5}}`))
+}
+
+func (s *authServer) grantTypes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.grants...)
+}
+
+func newAuthClient(url string) *DeribitRestClient {
+	return &DeribitRestClient{
+		Client:    http.DefaultClient,
+		ClientID:  "test-key",
+		ApiSecret: "test-secret",
+		BaseURL:   url,
+		Logger:    logrus.New(),
+	}
+}
+
+func TestLazyAuth(t *testing.T) {
+	auth := &authServer{}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	client := newAuthClient(server.URL)
+
+	var wg sync.WaitGroup
+	var failed atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"}); err != nil {
+				failed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Zero(t, failed.Load())
+	assert.Equal(t, []string{"client_credentials"}, auth.grantTypes())
+	assert.Equal(t, "token-1", client.AccessToken())
+	assert.WithinDuration(t, time.Now().Add(900*time.Second), client.TokenExpiresAt(), 5*time.Second)
+}
+
+func TestTokenRefresh(t *testing.T) {
+	auth := &authServer{}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	client := newAuthClient(server.URL)
+	_, err := client.GetAuthToken()
+	assert.NoError(t, err)
+
+	// Move the token past its refresh point.
+	client.mu.Lock()
+	client.auth.issuedAt = client.auth.issuedAt.Add(-800 * time.Second)
+	client.auth.expiresAt = client.auth.expiresAt.Add(-800 * time.Second)
+	client.mu.Unlock()
+
+	_, err = client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"client_credentials", "refresh_token"}, auth.grantTypes())
+	assert.Equal(t, "token-2", client.AccessToken())
+}
+
+func TestReauthOnInvalidToken(t *testing.T) {
+	auth := &authServer{}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	client := newAuthClient(server.URL)
+	client.SetAccessToken("revoked")
+
+	summary, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, summary.Equity)
+	assert.Equal(t, []string{"client_credentials"}, auth.grantTypes())
+
+	// A call that is still rejected after re-authenticating is not retried
+	// again.
+	auth.mu.Lock()
+	auth.rejectAll = true
+	auth.mu.Unlock()
+	_, err = client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.True(t, errors.Is(err, deribit.ErrInvalidToken))
+	assert.Equal(t, []string{"client_credentials", "client_credentials"}, auth.grantTypes())
+}
+
+func TestSetAccessTokenWithoutCredentials(t *testing.T) {
+	auth := &authServer{}
+	server := httptest.NewServer(auth)
+	defer server.Close()
+
+	client := &DeribitRestClient{Client: http.DefaultClient, BaseURL: server.URL, Logger: logrus.New()}
+	client.SetAccessToken("revoked")
+
+	_, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.True(t, errors.Is(err, deribit.ErrInvalidToken))
+	assert.Empty(t, auth.grantTypes())
+}
@@ -1,384 +1,185 @@
 package rest
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
-	"github.com/xingxing/deribit-api/pkg/deribit"
-	"github.com/xingxing/deribit-api/pkg/models"
-	"github.com/sirupsen/logrus"
-	"io"
-	"net/url"
-	"strings"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
-	"net/http"
+	"github.com/sirupsen/logrus"
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/instruments"
+	"github.com/xingxing/deribit-api/pkg/models"
 )
 
 const (
-	StdDepth    = 10
+	StdDepth = 10
+	// BtcTickSize is the tick size of BTC futures.
+	//
+	// Deprecated: PlaceLimitOrder rounds with the tick size of each
+	// instrument; see pkg/instruments.
 	BtcTickSize = 0.5
 )
 
 type DeribitRestClient struct {
-	Client      *http.Client
-	ClientID    string
-	ApiSecret   string
-	BaseURL     string
-	AccessToken *string
-	Logger      *logrus.Logger
+	Client    *http.Client
+	ClientID  string
+	ApiSecret string
+	BaseURL   string
+	Logger    *logrus.Logger
+	// SignatureAuth signs private requests with the deri-hmac-sha256
+	// Authorization scheme instead of sending a bearer token, and makes
+	// GetAuthToken use the client_signature grant.
+	SignatureAuth bool
+	// Retry controls retries of idempotent calls. Nil disables retries.
+	Retry *deribit.RetryPolicy
+	// RateLimiter, if set, is consulted before every request.
+	RateLimiter deribit.RateLimiter
+	// Instruments supplies the metadata PlaceLimitOrder rounds with. Nil
+	// means a cache loaded through this client on first use.
+	Instruments *instruments.Cache
+
+	instrumentsOnce sync.Once
+
+	// authMu serializes authentication round trips; mu guards auth.
+	authMu sync.Mutex
+	mu     sync.RWMutex
+	auth   authState
 }
 
 func NewDeribitRestClient(cfg *deribit.Configuration) *DeribitRestClient {
-	return &DeribitRestClient{
-		Client:      &http.Client{},
-		ClientID:    cfg.ApiKey,
-		ApiSecret:   cfg.SecretKey,
-		BaseURL:     cfg.RestAddr,
-		AccessToken: nil,
-		Logger:      cfg.Logger,
-	}
-}
-
-func (d *DeribitRestClient) GetAuthToken() (string, error) {
-	baseURL := strings.Replace(d.BaseURL, "/ws", "", 1)
-	d.Logger.Debugf("Original Base URL: %s", baseURL)
-
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	d.Logger.Debugf("URL after trim: %s", baseURL)
-
-	authURL := baseURL + "/public/auth"
-	d.Logger.Debugf("Final auth URL: %s", authURL)
-
-	params := url.Values{}
-	params.Set("grant_type", "client_credentials")
-	params.Set("client_id", d.ClientID)
-	params.Set("client_secret", d.ApiSecret)
-
-	fullURL := authURL + "?" + params.Encode()
-	d.Logger.Debugf("Full URL with params: %s", fullURL)
-
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := d.Client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			d.Logger.Errorf("Failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	d.Logger.Debugf("Response status: %s", resp.Status)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
-	}
-	d.Logger.Debugf("Response body: %s", string(body))
-
-	var result struct {
-		Result struct {
-			AccessToken string `json:"access_token"`
-		} `json:"result"`
-		Error *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if result.Error != nil {
-		return "", fmt.Errorf("API error %d: %s", result.Error.Code, result.Error.Message)
+	retry := cfg.Retry
+	if retry == nil {
+		retry = deribit.DefaultRetryPolicy()
 	}
-
-	d.AccessToken = &result.Result.AccessToken
-	return *d.AccessToken, nil
-}
-
-func (d *DeribitRestClient) request(method string, params map[string]interface{}, private bool) (map[string]interface{}, error) {
-	baseURL := strings.Replace(d.BaseURL, "/ws", "", 1)
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	fullURL := baseURL + "/" + method
-
-	d.Logger.Debugf("Request URL: %s", fullURL)
-	d.Logger.Debugf("Request params: %+v", params)
-
-	urlValues := url.Values{}
-	for key, value := range params {
-		urlValues.Set(key, fmt.Sprintf("%v", value))
-	}
-
-	fullURL = fullURL + "?" + urlValues.Encode()
-
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if private && d.AccessToken != nil {
-		req.Header.Set("Authorization", "Bearer "+*d.AccessToken)
-	}
-
-	resp, err := d.Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			d.Logger.Errorf("Failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-	d.Logger.Debugf("Response body: %s", string(body))
-
-	var result struct {
-		JSONRPC string                 `json:"jsonrpc"`
-		ID      int                    `json:"id"`
-		Result  map[string]interface{} `json:"result"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("API error %d: %s", result.Error.Code, result.Error.Message)
+	return &DeribitRestClient{
+		Client:        cfg.HTTPClient(),
+		ClientID:      cfg.ApiKey,
+		ApiSecret:     cfg.SecretKey,
+		BaseURL:       cfg.RestAddr,
+		Logger:        cfg.Logger,
+		SignatureAuth: cfg.SignatureAuth,
+		Retry:         retry,
+		RateLimiter:   cfg.RateLimiter,
 	}
-
-	return result.Result, nil
 }
 
-// requestInterface makes a request to the Deribit API and returns the result as interface{}
-func (d *DeribitRestClient) requestInterface(method string, params map[string]interface{}, private bool) (interface{}, error) {
-	baseURL := strings.Replace(d.BaseURL, "/ws", "", 1)
-	baseURL = strings.TrimSuffix(baseURL, "/")
-	fullURL := baseURL + "/" + method
-
-	d.Logger.Debugf("Request URL: %s", fullURL)
-	d.Logger.Debugf("Request params: %+v", params)
-
-	urlValues := url.Values{}
-	for key, value := range params {
-		urlValues.Set(key, fmt.Sprintf("%v", value))
-	}
-
-	fullURL = fullURL + "?" + urlValues.Encode()
-
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if private && d.AccessToken != nil {
-		req.Header.Set("Authorization", "Bearer "+*d.AccessToken)
-	}
-
-	resp, err := d.Client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			d.Logger.Errorf("Failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-	d.Logger.Debugf("Response body: %s", string(body))
-
-	var result struct {
-		JSONRPC string      `json:"jsonrpc"`
-		ID      int         `json:"id"`
-		Result  interface{} `json:"result"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("API error %d: %s", result.Error.Code, result.Error.Message)
-	}
-
-	return result.Result, nil
+func (d *DeribitRestClient) GetOrderbook(instrument string, depth *int) (restmodels.OrderBook, error) {
+	return d.GetOrderbookContext(context.Background(), instrument, depth)
 }
 
-func (d *DeribitRestClient) GetOrderbook(instrument string, depth *int) (restmodels.OrderBook, error) {
+func (d *DeribitRestClient) GetOrderbookContext(ctx context.Context, instrument string, depth *int) (restmodels.OrderBook, error) {
 	depthValue := StdDepth
 	if depth != nil {
 		depthValue = *depth
 	}
 
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-		"depth":           depthValue,
-	}
-
 	d.Logger.Debugf("Getting orderbook for %s with depth %d", instrument, depthValue)
 
-	result, err := d.request("public/get_order_book", params, false)
+	orderBook, err := Call[restmodels.OrderBook](ctx, d, "public/get_order_book", &models.GetOrderBookParams{
+		InstrumentName: instrument,
+		Depth:          depthValue,
+	})
 	if err != nil {
 		return restmodels.OrderBook{}, fmt.Errorf("failed to get orderbook: %w", err)
 	}
 
-	var orderBook restmodels.OrderBook
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return restmodels.OrderBook{}, fmt.Errorf("failed to marshal result: %w", err)
-	}
-
-	d.Logger.Debugf("Orderbook raw data: %s", string(resultBytes))
-
-	if err := json.Unmarshal(resultBytes, &orderBook); err != nil {
-		return restmodels.OrderBook{}, fmt.Errorf("failed to unmarshal order book: %w", err)
-	}
-
 	d.Logger.Debugf("Parsed orderbook: %+v", orderBook)
 	return orderBook, nil
 }
 
 func (d *DeribitRestClient) GetTicker(instrument string) (decimal.Decimal, error) {
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-	}
+	return d.GetTickerContext(context.Background(), instrument)
+}
 
-	response, err := d.request("public/ticker", params, false)
+func (d *DeribitRestClient) GetTickerContext(ctx context.Context, instrument string) (decimal.Decimal, error) {
+	// decimal.Decimal accepts the price as a JSON number or string.
+	ticker, err := Call[struct {
+		LastPrice *decimal.Decimal `json:"last_price"`
+	}](ctx, d, "public/ticker", &models.TickerParams{InstrumentName: instrument})
 	if err != nil {
 		return decimal.Zero, err
 	}
-
-	lastPrice, exists := response["last_price"]
-	if !exists {
+	if ticker.LastPrice == nil {
 		return decimal.Zero, errors.New("missing last_price field in response")
 	}
-
-	switch v := lastPrice.(type) {
-	case float64:
-		return decimal.NewFromFloat(v), nil
-	case string:
-		return decimal.NewFromString(v)
-	default:
-		return decimal.Zero, errors.New("last price is neither a number nor a string")
-	}
+	return *ticker.LastPrice, nil
 }
 
+// PlaceLimitOrder places a post-only limit order and returns the order.
+// The price is rounded to the instrument's tick and the amount down to its
+// amount step; orders the instrument cannot accept fail with
+// instruments.ErrInvalidOrder without being sent. Use PlaceOrder for other
+// order types and for the trades an order filled.
 func (d *DeribitRestClient) PlaceLimitOrder(instrument string,
 	price decimal.Decimal,
 	amount decimal.Decimal,
 	direction restmodels.Direction) (restmodels.Order, error) {
-	priceToTick := roundToTickSize(price, decimal.NewFromFloat(BtcTickSize))
-	method := "private/sell"
-	if direction == "buy" {
-		method = "private/buy"
-	}
-
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-		"price":           priceToTick.String(),
-		"amount":          amount.String(),
-		"type":            models.OrderTypeLimit,
-		"post_only":       true,
-	}
+	return d.PlaceLimitOrderContext(context.Background(), instrument, price, amount, direction)
+}
 
-	orderResult, err := d.request(method, params, true)
+func (d *DeribitRestClient) PlaceLimitOrderContext(ctx context.Context,
+	instrument string,
+	price decimal.Decimal,
+	amount decimal.Decimal,
+	direction restmodels.Direction) (restmodels.Order, error) {
+	result, err := d.PlaceOrderContext(ctx, NewOrder(direction, instrument, amount).Limit(price).PostOnly())
 	if err != nil {
 		return restmodels.Order{}, err
 	}
+	return result.Order, nil
+}
 
-	order := restmodels.Order{}
-	orderData, _ := json.Marshal(orderResult["order"])
-	if err := json.Unmarshal(orderData, &order); err != nil {
-		return restmodels.Order{}, err
-	}
-
-	return order, nil
+// instrumentCache returns d.Instruments, creating it on first use.
+func (d *DeribitRestClient) instrumentCache() *instruments.Cache {
+	d.instrumentsOnce.Do(func() {
+		if d.Instruments == nil {
+			d.Instruments = instruments.NewCache(d)
+		}
+	})
+	return d.Instruments
 }
 
 func (d *DeribitRestClient) GetRecentTrades(instrument string, count int) ([]models.Trade, error) {
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-		"count":           count,
-	}
+	return d.GetRecentTradesContext(context.Background(), instrument, count)
+}
 
+func (d *DeribitRestClient) GetRecentTradesContext(ctx context.Context, instrument string, count int) ([]models.Trade, error) {
 	d.Logger.Debugf("Getting recent trades for %s with count %d", instrument, count)
 
-	result, err := d.request("public/get_last_trades_by_instrument", params, false)
+	result, err := Call[models.GetLastTradesResponse](ctx, d, "public/get_last_trades_by_instrument", &models.GetLastTradesByInstrumentParams{
+		InstrumentName: instrument,
+		Count:          count,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trades: %w", err)
 	}
 
-	trades, ok := result["trades"].([]interface{})
-	if !ok {
-		return nil, errors.New("invalid trades data format")
-	}
-
-	tradesData, err := json.Marshal(trades)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal trades: %w", err)
-	}
-
-	d.Logger.Debugf("Trades raw data: %s", string(tradesData))
-
-	var tradesList []models.Trade
-	if err := json.Unmarshal(tradesData, &tradesList); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal trades: %w", err)
-	}
-
-	d.Logger.Debugf("Parsed trades: %+v", tradesList)
-	return tradesList, nil
+	d.Logger.Debugf("Parsed trades: %+v", result.Trades)
+	return result.Trades, nil
 }
 
 // GetFundingRate retrieves the funding rate for a perpetual instrument
 func (d *DeribitRestClient) GetFundingRate(instrument string, startTime, endTime time.Time) (models.FundingRatePoint, error) {
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-		"start_timestamp": startTime.UnixNano() / 1e6,
-		"end_timestamp":   endTime.UnixNano() / 1e6,
-	}
+	return d.GetFundingRateContext(context.Background(), instrument, startTime, endTime)
+}
 
+func (d *DeribitRestClient) GetFundingRateContext(ctx context.Context, instrument string, startTime, endTime time.Time) (models.FundingRatePoint, error) {
 	d.Logger.Debugf("Getting funding rate for %s between %v and %v", instrument, startTime, endTime)
 
-	result, err := d.requestInterface("public/get_funding_rate_value", params, false)
+	rate, err := Call[float64](ctx, d, "public/get_funding_rate_value", &models.GetFundingRateValueParams{
+		InstrumentName: instrument,
+		StartTimestamp: startTime.UnixMilli(),
+		EndTimestamp:   endTime.UnixMilli(),
+	})
 	if err != nil {
 		return models.FundingRatePoint{}, fmt.Errorf("failed to get funding rate: %w", err)
 	}
 
-	rate, ok := result.(float64)
-	if !ok {
-		return models.FundingRatePoint{}, fmt.Errorf("unexpected funding rate format in response: %T", result)
-	}
-
 	fundingRate := models.FundingRatePoint{
 		Timestamp: startTime.Unix(),
 		Rate:      rate,
@@ -391,43 +192,32 @@ func (d *DeribitRestClient) GetFundingRate(instrument string, startTime, endTime
 
 // GetCurrentFundingRate is a convenience method that gets the most recent funding rate
 func (d *DeribitRestClient) GetCurrentFundingRate(instrument string) (models.FundingRatePoint, error) {
+	return d.GetCurrentFundingRateContext(context.Background(), instrument)
+}
+
+func (d *DeribitRestClient) GetCurrentFundingRateContext(ctx context.Context, instrument string) (models.FundingRatePoint, error) {
 	now := time.Now()
 	// Get the rate for the last hour
 	startTime := now.Add(-1 * time.Hour)
 
-	return d.GetFundingRate(instrument, startTime, now)
+	return d.GetFundingRateContext(ctx, instrument, startTime, now)
 }
 
 // GetBookSummary retrieves the book summary for a specific instrument
 func (d *DeribitRestClient) GetBookSummary(instrument string) ([]models.BookSummary, error) {
-	params := map[string]interface{}{
-		"instrument_name": instrument,
-	}
+	return d.GetBookSummaryContext(context.Background(), instrument)
+}
 
+func (d *DeribitRestClient) GetBookSummaryContext(ctx context.Context, instrument string) ([]models.BookSummary, error) {
 	d.Logger.Debugf("Getting book summary for %s", instrument)
 
-	result, err := d.requestInterface("public/get_book_summary_by_instrument", params, false)
+	summaries, err := Call[[]models.BookSummary](ctx, d, "public/get_book_summary_by_instrument", &models.GetBookSummaryByInstrumentParams{
+		InstrumentName: instrument,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get book summary: %w", err)
 	}
 
-	// The result is an array of book summaries
-	summariesData, ok := result.([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected response format: %T", result)
-	}
-
-	// Convert to JSON to properly unmarshal into BookSummary structs
-	jsonData, err := json.Marshal(summariesData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal book summaries: %w", err)
-	}
-
-	var summaries []models.BookSummary
-	if err := json.Unmarshal(jsonData, &summaries); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal book summaries: %w", err)
-	}
-
 	d.Logger.Debugf("Retrieved %d book summaries for %s", len(summaries), instrument)
 	return summaries, nil
 }
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,6 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/instruments"
 )
 
 func TestNewDeribitRestClient(t *testing.T) {
@@ -100,9 +102,18 @@ func TestGetOrderbook(t *testing.T) {
 	assert.NotEmpty(t, orderbook.Bids)
 }
 
+// instrumentsResponse lists BTC-PERPETUAL for the instrument cache.
+const instrumentsResponse = `{"jsonrpc":"2.0","id":1,"result":[{"instrument_name":"BTC-PERPETUAL","kind":"future","is_active":true,"tick_size":0.5,"contract_size":1,"min_trade_amount":1}]}`
+
 func TestPlaceLimitOrder(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public/get_instruments" {
+			_, _ = w.Write([]byte(instrumentsResponse))
+			return
+		}
 		assert.Contains(t, r.URL.Path, "/private/")
+		assert.Equal(t, "9000.5", r.URL.Query().Get("price"))
+		assert.Equal(t, "1", r.URL.Query().Get("amount"))
 
 		response := map[string]interface{}{
 			"jsonrpc": "2.0",
@@ -124,11 +135,11 @@ func TestPlaceLimitOrder(t *testing.T) {
 	defer server.Close()
 
 	client := &DeribitRestClient{
-		Client:      http.DefaultClient,
-		BaseURL:     server.URL,
-		Logger:      logrus.New(),
-		AccessToken: stringPtr("test-token"),
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
 	}
+	client.SetAccessToken("test-token")
 
 	price := decimal.NewFromFloat(9000.5)
 	amount := decimal.NewFromFloat(1.0)
@@ -233,10 +244,6 @@ func TestGetCurrentFundingRate(t *testing.T) {
 	assert.Equal(t, 0.0001, rate.Rate)
 }
 
-func stringPtr(s string) *string {
-	return &s
-}
-
 func TestGetBookSummaryByInstrument(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/public/get_book_summary_by_instrument", r.URL.Path)
@@ -302,3 +309,62 @@ func TestGetBookSummaryByInstrument(t *testing.T) {
 	assert.Equal(t, "ETH", summary.BaseCurrency)
 	assert.Equal(t, 0.34, summary.AskPrice)
 }
+
+func TestRequestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public/get_instruments" {
+			_, _ = w.Write([]byte(instrumentsResponse))
+			return
+		}
+		response := `{
+            "jsonrpc": "2.0",
+            "id": 1,
+            "error": {"code": 10009, "message": "not_enough_funds", "data": {"reason": "margin"}}
+        }`
+		_, err := w.Write([]byte(response))
+		if err != nil {
+			return
+		}
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
+	}
+	client.SetAccessToken("test-token")
+
+	_, err := client.PlaceLimitOrder("BTC-PERPETUAL", decimal.NewFromInt(9000), decimal.NewFromInt(10), "buy")
+	assert.True(t, errors.Is(err, deribit.ErrNotEnoughFunds))
+
+	apiErr, ok := deribit.AsAPIError(err)
+	assert.True(t, ok)
+	assert.Equal(t, "not_enough_funds", apiErr.Message)
+	assert.JSONEq(t, `{"reason": "margin"}`, string(apiErr.Data))
+}
+
+func TestPlaceLimitOrderInvalid(t *testing.T) {
+	orders := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public/get_instruments" {
+			_, _ = w.Write([]byte(instrumentsResponse))
+			return
+		}
+		orders++
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
+	}
+	client.SetAccessToken("test-token")
+
+	_, err := client.PlaceLimitOrder("BTC-PERPETUAL", decimal.NewFromInt(9000), decimal.NewFromFloat(0.5), "buy")
+	assert.True(t, errors.Is(err, instruments.ErrInvalidOrder), "%v", err)
+	_, err = client.PlaceLimitOrder("BTC-31DEC99", decimal.NewFromInt(9000), decimal.NewFromInt(10), "sell")
+	assert.True(t, errors.Is(err, instruments.ErrUnknownInstrument), "%v", err)
+	assert.Zero(t, orders)
+}
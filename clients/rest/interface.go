@@ -0,0 +1,7 @@
+package rest
+
+import "github.com/xingxing/deribit-api/clients/websocket"
+
+var _ websocket.Behavior = (*DeribitRestClient)(nil)
+
+var _ websocket.OrderPlacer = (*DeribitRestClient)(nil)
@@ -14,8 +14,38 @@ type OrderResult struct {
 type OrderType string
 
 const (
-	Market OrderType = "market"
-	Limit  OrderType = "limit"
+	Market       OrderType = "market"
+	Limit        OrderType = "limit"
+	StopLimit    OrderType = "stop_limit"
+	StopMarket   OrderType = "stop_market"
+	TakeLimit    OrderType = "take_limit"
+	TakeMarket   OrderType = "take_market"
+	TrailingStop OrderType = "trailing_stop"
+)
+
+type TimeInForce string
+
+const (
+	GoodTilCancelled  TimeInForce = "good_til_cancelled"
+	GoodTilDay        TimeInForce = "good_til_day"
+	FillOrKill        TimeInForce = "fill_or_kill"
+	ImmediateOrCancel TimeInForce = "immediate_or_cancel"
+)
+
+type Trigger string
+
+const (
+	IndexPrice Trigger = "index_price"
+	MarkPrice  Trigger = "mark_price"
+	LastPrice  Trigger = "last_price"
+)
+
+// Advanced selects how an option order is priced.
+type Advanced string
+
+const (
+	AdvancedUSD   Advanced = "usd"
+	AdvancedImplV Advanced = "implv"
 )
 
 type OrderStatus string
@@ -49,6 +79,10 @@ type Order struct {
 	AveragePrice   float64     `json:"average_price"            decimal_format:"deserialize"`
 	API            bool        `json:"api"`
 	Amount         float64     `json:"amount"                   decimal_format:"deserialize"`
+	Trigger        Trigger     `json:"trigger"`
+	TriggerPrice   float64     `json:"trigger_price"            decimal_format:"deserialize"`
+	TriggerOffset  float64     `json:"trigger_offset"           decimal_format:"deserialize"`
+	Advanced       Advanced    `json:"advanced"`
 }
 
 type Orders []Order
@@ -1,9 +1,11 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
 	"math/big"
 	"sort"
 	"time"
@@ -33,6 +35,42 @@ type OrderBook struct {
 	Asks      PriceLevels
 	Bids      PriceLevels
 	Timestamp time.Time
+	// ChangeID is the change_id the book is at, which book.{instrument}.raw
+	// notifications continue from.
+	ChangeID int64
+}
+
+// Depth converts the book for the analytics of the orderbook package, such
+// as VWAP, SlippageBps and Microprice.
+func (o OrderBook) Depth() orderbook.Depth {
+	return orderbook.Depth{Bids: o.Bids.levels(), Asks: o.Asks.levels()}
+}
+
+// Snapshot converts the book of instrument to the orderbook package's book
+// value.
+func (o OrderBook) Snapshot(instrument string) orderbook.Snapshot {
+	return orderbook.Snapshot{Depth: o.Depth(), Instrument: instrument, ChangeID: o.ChangeID, Timestamp: o.Timestamp}
+}
+
+// FromSnapshot converts s back to an OrderBook.
+func FromSnapshot(s orderbook.Snapshot) OrderBook {
+	return OrderBook{Asks: priceLevels(s.Asks), Bids: priceLevels(s.Bids), Timestamp: s.Timestamp, ChangeID: s.ChangeID}
+}
+
+func priceLevels(levels []orderbook.Level) PriceLevels {
+	p := make(PriceLevels, len(levels))
+	for i, level := range levels {
+		p[i] = PriceLevel{Price: decimal.NewFromFloat(level.Price), Amount: decimal.NewFromFloat(level.Amount)}
+	}
+	return p
+}
+
+func (p PriceLevels) levels() []orderbook.Level {
+	levels := make([]orderbook.Level, len(p))
+	for i, level := range p {
+		levels[i] = orderbook.Level{Price: level.Price.InexactFloat64(), Amount: level.Amount.InexactFloat64()}
+	}
+	return levels
 }
 
 func (p PriceLevels) Len() int           { return len(p) }
@@ -40,8 +78,12 @@ func (p PriceLevels) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 func (p PriceLevels) Less(i, j int) bool { return p[i].Price.Cmp(p[j].Price) < 0 }
 
 func (o *OrderBook) UnmarshalJSON(data []byte) error {
+	// Numbers are kept as json.Number so that levels parse without a
+	// round-trip through float64.
 	var raw map[string]interface{}
-	if err := json.Unmarshal(data, &raw); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
 		return err
 	}
 
@@ -51,15 +93,12 @@ func (o *OrderBook) UnmarshalJSON(data []byte) error {
 	if asksRaw, ok := raw["asks"].([]interface{}); ok {
 		for _, v := range asksRaw {
 			if level, ok := v.([]interface{}); ok && len(level) >= 2 {
-				price, ok1 := level[0].(float64)
-				amount, ok2 := level[1].(float64)
+				price, ok1 := parseNumber(level[0])
+				amount, ok2 := parseNumber(level[1])
 				if !ok1 || !ok2 {
 					return fmt.Errorf("invalid ask price/amount format")
 				}
-				asks = append(asks, PriceLevel{
-					Price:  decimal.NewFromFloat(price),
-					Amount: decimal.NewFromFloat(amount),
-				})
+				asks = append(asks, PriceLevel{Price: price, Amount: amount})
 			}
 		}
 	}
@@ -68,22 +107,28 @@ func (o *OrderBook) UnmarshalJSON(data []byte) error {
 	if bidsRaw, ok := raw["bids"].([]interface{}); ok {
 		for _, v := range bidsRaw {
 			if level, ok := v.([]interface{}); ok && len(level) >= 2 {
-				price, ok1 := level[0].(float64)
-				amount, ok2 := level[1].(float64)
+				price, ok1 := parseNumber(level[0])
+				amount, ok2 := parseNumber(level[1])
 				if !ok1 || !ok2 {
 					return fmt.Errorf("invalid bid price/amount format")
 				}
-				bids = append(bids, PriceLevel{
-					Price:  decimal.NewFromFloat(price),
-					Amount: decimal.NewFromFloat(amount),
-				})
+				bids = append(bids, PriceLevel{Price: price, Amount: amount})
 			}
 		}
 	}
 
 	// Parse timestamp
-	if ts, ok := raw["timestamp"].(float64); ok {
-		o.Timestamp = time.Unix(0, int64(ts)*int64(time.Millisecond))
+	if ts, ok := raw["timestamp"].(json.Number); ok {
+		if ms, err := ts.Int64(); err == nil {
+			o.Timestamp = time.UnixMilli(ms)
+		}
+	}
+
+	// Parse change id
+	if id, ok := raw["change_id"].(json.Number); ok {
+		if changeID, err := id.Int64(); err == nil {
+			o.ChangeID = changeID
+		}
 	}
 
 	sort.Sort(sort.Reverse(bids)) // Sort bids in descending order
@@ -93,3 +138,12 @@ func (o *OrderBook) UnmarshalJSON(data []byte) error {
 	o.Bids = bids
 	return nil
 }
+
+func parseNumber(v interface{}) (decimal.Decimal, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return decimal.Decimal{}, false
+	}
+	d, err := decimal.NewFromString(n.String())
+	return d, err == nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
 	"math/big"
 	"testing"
 	"time"
@@ -98,9 +99,11 @@ func TestOrderBook_UnmarshalJSON(t *testing.T) {
 			jsonData: `{
 				"asks": [[9100.5, 1.5], [9200.0, 2.0]],
 				"bids": [[9000.0, 1.0], [8900.5, 2.5]],
-				"timestamp": 1609459200000
+				"timestamp": 1609459200000,
+				"change_id": 31436184
 			}`,
 			expectedBook: OrderBook{
+				ChangeID: 31436184,
 				Asks: PriceLevels{
 					{Price: decimal.NewFromFloat(9100.5), Amount: decimal.NewFromFloat(1.5)},
 					{Price: decimal.NewFromFloat(9200.0), Amount: decimal.NewFromFloat(2.0)},
@@ -112,6 +115,23 @@ func TestOrderBook_UnmarshalJSON(t *testing.T) {
 				Timestamp: time.Unix(0, 1609459200000*int64(time.Millisecond)),
 			},
 		},
+		{
+			name: "lossless_levels",
+			jsonData: `{
+				"asks": [[0.1234567890123456789, 12345678901234.56789]],
+				"bids": [[0.0001, 0.3]],
+				"timestamp": 1609459200000
+			}`,
+			expectedBook: OrderBook{
+				Asks: PriceLevels{
+					{Price: decimal.RequireFromString("0.1234567890123456789"), Amount: decimal.RequireFromString("12345678901234.56789")},
+				},
+				Bids: PriceLevels{
+					{Price: decimal.RequireFromString("0.0001"), Amount: decimal.RequireFromString("0.3")},
+				},
+				Timestamp: time.Unix(0, 1609459200000*int64(time.Millisecond)),
+			},
+		},
 		{
 			name:          "invalid_json",
 			jsonData:      `{invalid json`,
@@ -168,6 +188,7 @@ func TestOrderBook_UnmarshalJSON(t *testing.T) {
 
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedBook.Timestamp, ob.Timestamp)
+			assert.Equal(t, tt.expectedBook.ChangeID, ob.ChangeID)
 
 			// Verify asks
 			assert.Equal(t, len(tt.expectedBook.Asks), len(ob.Asks))
@@ -193,3 +214,38 @@ func TestOrderBook_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderBook_Depth(t *testing.T) {
+	var ob OrderBook
+	err := json.Unmarshal([]byte(`{"asks": [[101, 1], [102, 4]], "bids": [[99, 3], [100, 2]]}`), &ob)
+	assert.NoError(t, err)
+
+	depth := ob.Depth()
+	assert.Equal(t, []orderbook.Level{{Price: 100, Amount: 2}, {Price: 99, Amount: 3}}, depth.Bids)
+	assert.Equal(t, []orderbook.Level{{Price: 101, Amount: 1}, {Price: 102, Amount: 4}}, depth.Asks)
+
+	vwap, err := depth.VWAP(orderbook.Asks, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 101.5, vwap)
+}
+
+func TestOrderBook_Snapshot(t *testing.T) {
+	var ob OrderBook
+	err := json.Unmarshal([]byte(`{"asks": [[101, 1], [102, 4]], "bids": [[99, 3], [100.5, 2]], "timestamp": 1609459200000, "change_id": 42}`), &ob)
+	assert.NoError(t, err)
+
+	s := ob.Snapshot("BTC-PERPETUAL")
+	assert.Equal(t, "BTC-PERPETUAL", s.Instrument)
+	assert.Equal(t, ob.Timestamp, s.Timestamp)
+	assert.Equal(t, int64(42), s.ChangeID)
+	assert.Equal(t, ob.Depth(), s.Depth)
+
+	back := FromSnapshot(s)
+	assert.Equal(t, ob.Timestamp, back.Timestamp)
+	assert.Equal(t, int64(42), back.ChangeID)
+	if assert.Len(t, back.Bids, 2) && assert.Len(t, back.Asks, 2) {
+		assert.True(t, back.Bids[0].Price.Equal(decimal.RequireFromString("100.5")))
+		assert.True(t, back.Bids[1].Amount.Equal(decimal.NewFromInt(3)))
+		assert.True(t, back.Asks[1].Price.Equal(decimal.NewFromInt(102)))
+	}
+}
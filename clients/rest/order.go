@@ -0,0 +1,276 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+	"github.com/xingxing/deribit-api/pkg/instruments"
+)
+
+// OrderRequest describes an order for PlaceOrder. Start one with NewOrder,
+// pick the type with Market, Limit, StopLimit, StopMarket, TakeLimit,
+// TakeMarket or TrailingStop, and add options with the other methods:
+//
+//	req := rest.NewOrder(restmodels.Sell, "BTC-PERPETUAL", decimal.NewFromInt(100)).
+//		StopMarket(decimal.NewFromInt(60000), restmodels.MarkPrice).
+//		ReduceOnly()
+type OrderRequest struct {
+	direction     restmodels.Direction
+	instrument    string
+	amount        decimal.Decimal
+	orderType     restmodels.OrderType
+	price         decimal.Decimal
+	triggerPrice  decimal.Decimal
+	triggerOffset decimal.Decimal
+	trigger       restmodels.Trigger
+	timeInForce   restmodels.TimeInForce
+	maxShow       *decimal.Decimal
+	postOnly      bool
+	reduceOnly    bool
+	label         string
+	advanced      restmodels.Advanced
+}
+
+// NewOrder starts a market order for amount of instrument.
+func NewOrder(direction restmodels.Direction, instrument string, amount decimal.Decimal) *OrderRequest {
+	return &OrderRequest{
+		direction:  direction,
+		instrument: instrument,
+		amount:     amount,
+		orderType:  restmodels.Market,
+	}
+}
+
+// Market makes a market order.
+func (o *OrderRequest) Market() *OrderRequest {
+	o.orderType = restmodels.Market
+	return o
+}
+
+// Limit makes a limit order at price.
+func (o *OrderRequest) Limit(price decimal.Decimal) *OrderRequest {
+	o.orderType = restmodels.Limit
+	o.price = price
+	return o
+}
+
+// StopLimit makes a limit order at price that is placed once trigger
+// crosses triggerPrice.
+func (o *OrderRequest) StopLimit(triggerPrice decimal.Decimal, trigger restmodels.Trigger, price decimal.Decimal) *OrderRequest {
+	o.orderType = restmodels.StopLimit
+	o.triggerPrice, o.trigger, o.price = triggerPrice, trigger, price
+	return o
+}
+
+// StopMarket makes a market order that is placed once trigger crosses
+// triggerPrice.
+func (o *OrderRequest) StopMarket(triggerPrice decimal.Decimal, trigger restmodels.Trigger) *OrderRequest {
+	o.orderType = restmodels.StopMarket
+	o.triggerPrice, o.trigger = triggerPrice, trigger
+	return o
+}
+
+// TakeLimit makes a take-profit limit order at price.
+func (o *OrderRequest) TakeLimit(triggerPrice decimal.Decimal, trigger restmodels.Trigger, price decimal.Decimal) *OrderRequest {
+	o.orderType = restmodels.TakeLimit
+	o.triggerPrice, o.trigger, o.price = triggerPrice, trigger, price
+	return o
+}
+
+// TakeMarket makes a take-profit market order.
+func (o *OrderRequest) TakeMarket(triggerPrice decimal.Decimal, trigger restmodels.Trigger) *OrderRequest {
+	o.orderType = restmodels.TakeMarket
+	o.triggerPrice, o.trigger = triggerPrice, trigger
+	return o
+}
+
+// TrailingStop makes a stop market order whose trigger price trails the
+// trigger by offset.
+func (o *OrderRequest) TrailingStop(offset decimal.Decimal, trigger restmodels.Trigger) *OrderRequest {
+	o.orderType = restmodels.TrailingStop
+	o.triggerOffset, o.trigger = offset, trigger
+	return o
+}
+
+// PostOnly rejects or reprices a limit order that would take liquidity.
+func (o *OrderRequest) PostOnly() *OrderRequest {
+	o.postOnly = true
+	return o
+}
+
+// ReduceOnly only lets the order reduce the position.
+func (o *OrderRequest) ReduceOnly() *OrderRequest {
+	o.reduceOnly = true
+	return o
+}
+
+// TimeInForce sets how long the order stays open. The default is
+// restmodels.GoodTilCancelled.
+func (o *OrderRequest) TimeInForce(tif restmodels.TimeInForce) *OrderRequest {
+	o.timeInForce = tif
+	return o
+}
+
+// MaxShow limits the amount shown in the book, for iceberg orders.
+func (o *OrderRequest) MaxShow(amount decimal.Decimal) *OrderRequest {
+	o.maxShow = &amount
+	return o
+}
+
+// Label tags the order with a user defined label.
+func (o *OrderRequest) Label(label string) *OrderRequest {
+	o.label = label
+	return o
+}
+
+// Advanced prices an option limit order in USD or implied volatility
+// instead of the base currency.
+func (o *OrderRequest) Advanced(advanced restmodels.Advanced) *OrderRequest {
+	o.advanced = advanced
+	return o
+}
+
+// ErrInvalidOrderRequest is wrapped by the errors of OrderRequest.Validate.
+var ErrInvalidOrderRequest = errors.New("invalid order request")
+
+// Validate checks that the options fit together.
+func (o *OrderRequest) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidOrderRequest, fmt.Sprintf(format, args...))
+	}
+	if o.direction != restmodels.Buy && o.direction != restmodels.Sell {
+		return invalid("direction %q", o.direction)
+	}
+	if o.instrument == "" {
+		return invalid("missing instrument")
+	}
+	if !o.amount.IsPositive() {
+		return invalid("amount %s is not positive", o.amount)
+	}
+
+	limit := false
+	switch o.orderType {
+	case restmodels.Market:
+	case restmodels.Limit:
+		limit = true
+	case restmodels.StopLimit, restmodels.TakeLimit:
+		limit = true
+		fallthrough
+	case restmodels.StopMarket, restmodels.TakeMarket:
+		if !o.triggerPrice.IsPositive() {
+			return invalid("%s order needs a trigger price", o.orderType)
+		}
+	case restmodels.TrailingStop:
+		if !o.triggerOffset.IsPositive() {
+			return invalid("trailing_stop order needs a trigger offset")
+		}
+	default:
+		return invalid("order type %q", o.orderType)
+	}
+	if limit && o.price.IsZero() {
+		return invalid("%s order needs a price", o.orderType)
+	}
+	if !limit && !o.price.IsZero() {
+		return invalid("%s order takes no price", o.orderType)
+	}
+	triggered := !o.triggerPrice.IsZero() || !o.triggerOffset.IsZero()
+	if triggered && o.trigger == "" {
+		return invalid("%s order needs a trigger", o.orderType)
+	}
+	if o.postOnly && !limit {
+		return invalid("post_only needs a limit price")
+	}
+	if o.postOnly && (o.timeInForce == restmodels.ImmediateOrCancel || o.timeInForce == restmodels.FillOrKill) {
+		return invalid("post_only cannot be combined with %s", o.timeInForce)
+	}
+	if o.maxShow != nil && !limit {
+		return invalid("max_show needs a limit price")
+	}
+	if o.advanced != "" && o.orderType != restmodels.Limit {
+		return invalid("advanced needs a limit order")
+	}
+	return nil
+}
+
+// orderParams is the request of private/buy and private/sell. Prices are
+// decimals so that they reach the server exactly as rounded.
+type orderParams struct {
+	InstrumentName string                 `json:"instrument_name"`
+	Amount         decimal.Decimal        `json:"amount"`
+	Type           restmodels.OrderType   `json:"type"`
+	Label          string                 `json:"label,omitempty"`
+	Price          *decimal.Decimal       `json:"price,omitempty"`
+	TimeInForce    restmodels.TimeInForce `json:"time_in_force,omitempty"`
+	MaxShow        *decimal.Decimal       `json:"max_show,omitempty"`
+	PostOnly       bool                   `json:"post_only,omitempty"`
+	ReduceOnly     bool                   `json:"reduce_only,omitempty"`
+	TriggerPrice   *decimal.Decimal       `json:"trigger_price,omitempty"`
+	TriggerOffset  *decimal.Decimal       `json:"trigger_offset,omitempty"`
+	Trigger        restmodels.Trigger     `json:"trigger,omitempty"`
+	Advanced       restmodels.Advanced    `json:"advanced,omitempty"`
+}
+
+func optional(d decimal.Decimal) *decimal.Decimal {
+	if d.IsZero() {
+		return nil
+	}
+	return &d
+}
+
+// PlaceOrder validates req, rounds its prices and amount to the
+// instrument and sends it. The result holds the order and any trades it
+// filled immediately.
+func (d *DeribitRestClient) PlaceOrder(req *OrderRequest) (restmodels.OrderResult, error) {
+	return d.PlaceOrderContext(context.Background(), req)
+}
+
+func (d *DeribitRestClient) PlaceOrderContext(ctx context.Context, req *OrderRequest) (result restmodels.OrderResult, err error) {
+	if err = req.Validate(); err != nil {
+		return
+	}
+	instrument, err := d.instrumentCache().Get(ctx, req.instrument)
+	if err != nil {
+		return
+	}
+	if req.advanced != "" && instrument.Kind != "option" {
+		err = fmt.Errorf("%w: advanced is only available for options", ErrInvalidOrderRequest)
+		return
+	}
+
+	// Advanced prices are in USD or volatility, not on the instrument's
+	// tick grid.
+	price, checkPrice := req.price, decimal.Zero
+	if !price.IsZero() && req.advanced == "" {
+		price = instruments.RoundPrice(instrument, price)
+		checkPrice = price
+	}
+	amount := instruments.RoundAmount(instrument, req.amount)
+	if err = instruments.Validate(instrument, checkPrice, amount); err != nil {
+		return
+	}
+	triggerPrice := req.triggerPrice
+	if !triggerPrice.IsZero() {
+		triggerPrice = instruments.RoundPrice(instrument, triggerPrice)
+	}
+
+	params := &orderParams{
+		InstrumentName: req.instrument,
+		Amount:         amount,
+		Type:           req.orderType,
+		Label:          req.label,
+		Price:          optional(price),
+		TimeInForce:    req.timeInForce,
+		MaxShow:        req.maxShow,
+		PostOnly:       req.postOnly,
+		ReduceOnly:     req.reduceOnly,
+		TriggerPrice:   optional(triggerPrice),
+		TriggerOffset:  optional(req.triggerOffset),
+		Trigger:        req.trigger,
+		Advanced:       req.advanced,
+	}
+	err = d.CallContext(ctx, "private/"+string(req.direction), params, &result)
+	return
+}
@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+)
+
+func TestOrderRequest_Validate(t *testing.T) {
+	amount := decimal.NewFromInt(10)
+	price := decimal.NewFromInt(60000)
+	tests := []struct {
+		name  string
+		req   *OrderRequest
+		valid bool
+	}{
+		{"market", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount), true},
+		{"limit", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).Limit(price).PostOnly().MaxShow(amount), true},
+		{"stop limit", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).StopLimit(price, restmodels.MarkPrice, price), true},
+		{"stop market", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).StopMarket(price, restmodels.IndexPrice), true},
+		{"take limit", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).TakeLimit(price, restmodels.LastPrice, price), true},
+		{"take market", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).TakeMarket(price, restmodels.LastPrice), true},
+		{"trailing stop", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).TrailingStop(decimal.NewFromInt(100), restmodels.MarkPrice), true},
+		{"ioc", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).Limit(price).TimeInForce(restmodels.ImmediateOrCancel), true},
+		{"bad direction", NewOrder("hold", "BTC-PERPETUAL", amount), false},
+		{"no instrument", NewOrder(restmodels.Buy, "", amount), false},
+		{"no amount", NewOrder(restmodels.Buy, "BTC-PERPETUAL", decimal.Zero), false},
+		{"limit without price", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).Limit(decimal.Zero), false},
+		{"stop without trigger price", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).StopMarket(decimal.Zero, restmodels.MarkPrice), false},
+		{"stop without trigger", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).StopMarket(price, ""), false},
+		{"trailing without offset", NewOrder(restmodels.Sell, "BTC-PERPETUAL", amount).TrailingStop(decimal.Zero, restmodels.MarkPrice), false},
+		{"post only market", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).PostOnly(), false},
+		{"post only fok", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).Limit(price).PostOnly().TimeInForce(restmodels.FillOrKill), false},
+		{"max show market", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).MaxShow(amount), false},
+		{"advanced market", NewOrder(restmodels.Buy, "BTC-PERPETUAL", amount).Advanced(restmodels.AdvancedUSD), false},
+	}
+	for _, tt := range tests {
+		err := tt.req.Validate()
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.True(t, errors.Is(err, ErrInvalidOrderRequest), "%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestPlaceOrder(t *testing.T) {
+	var query url.Values
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public/get_instruments" {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[
+				{"instrument_name":"BTC-PERPETUAL","kind":"future","is_active":true,"tick_size":0.5,"contract_size":10,"min_trade_amount":10},
+				{"instrument_name":"BTC-27DEC30-100000-C","kind":"option","is_active":true,"tick_size":0.0001,"contract_size":1,"min_trade_amount":0.1}]}`))
+			return
+		}
+		path, query = r.URL.Path, r.URL.Query()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{
+			"order":{"order_id":"1","order_type":"stop_limit","trigger":"mark_price","trigger_price":60000.5},
+			"trades":[{"trade_id":"t1","price":60000.5,"amount":10}]}}`))
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{Client: http.DefaultClient, BaseURL: server.URL, Logger: logrus.New()}
+	client.SetAccessToken("test-token")
+
+	result, err := client.PlaceOrder(NewOrder(restmodels.Sell, "BTC-PERPETUAL", decimal.NewFromInt(25)).
+		StopLimit(decimal.RequireFromString("60000.4"), restmodels.MarkPrice, decimal.RequireFromString("59990.2")).
+		ReduceOnly().
+		TimeInForce(restmodels.GoodTilDay).
+		Label("exit"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/private/sell", path)
+	assert.Equal(t, url.Values{
+		"instrument_name": {"BTC-PERPETUAL"},
+		"amount":          {"20"},
+		"type":            {"stop_limit"},
+		"price":           {"59990"},
+		"trigger_price":   {"60000.5"},
+		"trigger":         {"mark_price"},
+		"reduce_only":     {"true"},
+		"time_in_force":   {"good_til_day"},
+		"label":           {"exit"},
+	}, query)
+	assert.Equal(t, restmodels.StopLimit, result.Order.OrderType)
+	assert.Equal(t, 60000.5, result.Order.TriggerPrice)
+	if assert.Len(t, result.Trades, 1) {
+		assert.Equal(t, "t1", result.Trades[0].TradeID)
+	}
+
+	// Prices in implied volatility are not rounded to the option tick.
+	_, err = client.PlaceOrder(NewOrder(restmodels.Buy, "BTC-27DEC30-100000-C", decimal.RequireFromString("0.5")).
+		Limit(decimal.RequireFromString("55.25")).
+		Advanced(restmodels.AdvancedImplV))
+	assert.NoError(t, err)
+	assert.Equal(t, "/private/buy", path)
+	assert.Equal(t, "55.25", query.Get("price"))
+	assert.Equal(t, "implv", query.Get("advanced"))
+
+	_, err = client.PlaceOrder(NewOrder(restmodels.Buy, "BTC-PERPETUAL", decimal.NewFromInt(10)).
+		Limit(decimal.NewFromInt(60000)).
+		Advanced(restmodels.AdvancedUSD))
+	assert.True(t, errors.Is(err, ErrInvalidOrderRequest), "%v", err)
+}
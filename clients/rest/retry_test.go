@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/ratelimit"
+)
+
+func TestRetry(t *testing.T) {
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls[r.URL.Path]++
+		if r.URL.Path == "/public/get_instruments" && calls[r.URL.Path] == 3 {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":[{"instrument_name":"BTC-PERPETUAL"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
+		Retry:   &deribit.RetryPolicy{Backoff: deribit.Backoff{InitialInterval: time.Millisecond}, MaxAttempts: 3},
+	}
+	client.SetAccessToken("test-token")
+
+	instruments, err := client.GetInstruments(&models.GetInstrumentsParams{Currency: "BTC"})
+	assert.NoError(t, err)
+	assert.Len(t, instruments, 1)
+	assert.Equal(t, 3, calls["/public/get_instruments"])
+
+	_, err = client.Sell(&models.SellParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: "market"})
+	var httpErr *deribit.HTTPError
+	assert.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	assert.Equal(t, 1, calls["/private/sell"])
+}
+
+func TestRateLimiter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":1700000000000}`))
+	}))
+	defer server.Close()
+
+	limiter := ratelimit.New(ratelimit.Config{
+		NonMatchingEngine: ratelimit.BucketConfig{Cost: 500, Capacity: 1000, Refill: 1},
+		FailFast:          true,
+	})
+	client := &DeribitRestClient{
+		Client:      http.DefaultClient,
+		BaseURL:     server.URL,
+		Logger:      logrus.New(),
+		Retry:       &deribit.RetryPolicy{Backoff: deribit.Backoff{InitialInterval: time.Millisecond}, MaxAttempts: 3},
+		RateLimiter: limiter,
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetTime()
+		assert.NoError(t, err)
+	}
+	_, err := client.GetTime()
+	assert.ErrorIs(t, err, ratelimit.ErrRateLimited)
+	assert.Equal(t, 2, requests)
+}
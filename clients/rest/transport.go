@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+// Call sends method with params and decodes the result into a T. params may
+// be nil, a url.Values, a map or one of the pkg/models param structs.
+func Call[T any](ctx context.Context, d *DeribitRestClient, method string, params interface{}) (T, error) {
+	var result T
+	err := d.CallContext(ctx, method, params, &result)
+	return result, err
+}
+
+// CallContext sends method with params as an HTTP GET request and decodes
+// the JSON-RPC result into result, which must be a pointer. Methods under
+// private/ carry the access token, authenticating first if needed; a call
+// rejected for its token is replayed once with a new one. Idempotent methods
+// that fail transiently are retried under d.Retry. Errors returned by the API
+// are *deribit.APIError.
+func (d *DeribitRestClient) CallContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	query, err := encodeParams(params)
+	if err != nil {
+		return fmt.Errorf("failed to encode params: %w", err)
+	}
+	return d.Retry.Do(ctx, method, func() error {
+		return d.call(ctx, method, query, result)
+	})
+}
+
+// call implements one attempt of CallContext.
+func (d *DeribitRestClient) call(ctx context.Context, method string, query url.Values, result interface{}) error {
+	if !strings.HasPrefix(method, "private/") {
+		return d.send(ctx, method, query, "", result)
+	}
+	if d.SignatureAuth && d.hasCredentials() {
+		return d.send(ctx, method, query, "", result)
+	}
+
+	token, err := d.validToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %w", err)
+	}
+	err = d.send(ctx, method, query, token, result)
+	if !isAuthError(err) || !d.hasCredentials() {
+		return err
+	}
+	d.Logger.Debugf("%s rejected the access token, authenticating again", method)
+	token, aErr := d.reauthenticate(ctx, token)
+	if aErr != nil {
+		return fmt.Errorf("failed to authenticate: %w", aErr)
+	}
+	return d.send(ctx, method, query, token, result)
+}
+
+// send performs one HTTP round trip, after waiting for the rate limiter.
+// token, if set, is sent as a bearer token.
+func (d *DeribitRestClient) send(ctx context.Context, method string, query url.Values, token string, result interface{}) (err error) {
+	if d.RateLimiter != nil {
+		if err := d.RateLimiter.Wait(ctx, method); err != nil {
+			return deribit.Permanent(err)
+		}
+		defer func() { d.RateLimiter.Observe(method, err) }()
+	}
+
+	fullURL := d.endpoint(method)
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+	d.Logger.Debugf("Request URL: %s", deribit.Redact(fullURL))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if strings.HasPrefix(method, "private/") {
+		d.authorize(req, token)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			d.Logger.Errorf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	d.Logger.Debugf("Response body: %s", deribit.Redact(string(body)))
+
+	// Result holds the caller's pointer, so the result is decoded in place.
+	envelope := struct {
+		Result interface{}       `json:"result"`
+		Error  *deribit.APIError `json:"error"`
+	}{Result: result}
+	dErr := json.Unmarshal(body, &envelope)
+	if dErr == nil && envelope.Error != nil {
+		return envelope.Error
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &deribit.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if dErr != nil {
+		return fmt.Errorf("failed to decode response (status %s): %w", resp.Status, dErr)
+	}
+	return nil
+}
+
+// authorize sets the Authorization header of a private request. With
+// signature auth every request is signed with the API secret, otherwise
+// token is sent as a bearer token.
+func (d *DeribitRestClient) authorize(req *http.Request, token string) {
+	switch {
+	case d.SignatureAuth && d.hasCredentials():
+		req.Header.Set("Authorization", deribit.AuthorizationHeader(d.ClientID, d.ApiSecret, req.Method, req.URL.RequestURI(), ""))
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// endpoint returns the HTTP URL of method.
+func (d *DeribitRestClient) endpoint(method string) string {
+	baseURL := strings.Replace(d.BaseURL, "/ws", "", 1)
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return baseURL + "/" + method
+}
+
+// encodeParams turns params into query values. Structs and maps go through
+// their JSON form, so the json tags of the param structs name the query keys
+// and numbers keep their exact text. Nested arrays and objects are sent as
+// JSON.
+func encodeParams(params interface{}) (url.Values, error) {
+	switch p := params.(type) {
+	case nil:
+		return nil, nil
+	case url.Values:
+		return p, nil
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("params must encode to a JSON object: %w", err)
+	}
+
+	values := url.Values{}
+	for key, value := range fields {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			values.Set(key, v)
+		case json.Number:
+			values.Set(key, v.String())
+		case bool:
+			values.Set(key, strconv.FormatBool(v))
+		default:
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			values.Set(key, string(encoded))
+		}
+	}
+	return values, nil
+}
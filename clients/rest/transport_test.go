@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestEncodeParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params interface{}
+		want   url.Values
+	}{
+		{"nil", nil, nil},
+		{"values", url.Values{"a": {"b"}}, url.Values{"a": {"b"}}},
+		{
+			"struct",
+			&models.GetOrderBookParams{InstrumentName: "BTC-PERPETUAL", Depth: 10},
+			url.Values{"instrument_name": {"BTC-PERPETUAL"}, "depth": {"10"}},
+		},
+		{
+			"large integers stay exact",
+			&models.GetFundingRateValueParams{InstrumentName: "BTC-PERPETUAL", StartTimestamp: 1700000000000, EndTimestamp: 1700003600000},
+			url.Values{"instrument_name": {"BTC-PERPETUAL"}, "start_timestamp": {"1700000000000"}, "end_timestamp": {"1700003600000"}},
+		},
+		{
+			"bools and nested values",
+			map[string]interface{}{"post_only": true, "legs": []map[string]interface{}{{"amount": 1}}, "skip": nil},
+			url.Values{"post_only": {"true"}, "legs": {`[{"amount":1}]`}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := encodeParams(tt.params)
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.want, got, tt.name)
+		}
+	}
+
+	_, err := encodeParams([]int{1})
+	assert.Error(t, err)
+}
+
+func TestCall(t *testing.T) {
+	var (
+		mu            sync.Mutex
+		authorization []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"trades":[{"trade_id":"1","price":9000.5}],"has_more":true}}`))
+	}))
+	defer server.Close()
+
+	client := &DeribitRestClient{
+		Client:  http.DefaultClient,
+		BaseURL: server.URL,
+		Logger:  logrus.New(),
+	}
+	client.SetAccessToken("test-token")
+
+	result, err := Call[models.GetLastTradesResponse](context.Background(), client, "public/get_last_trades_by_instrument", nil)
+	assert.NoError(t, err)
+	assert.True(t, result.HasMore)
+	if assert.Len(t, result.Trades, 1) {
+		assert.Equal(t, 9000.5, result.Trades[0].Price)
+	}
+
+	_, err = Call[models.GetLastTradesResponse](context.Background(), client, "private/get_user_trades_by_instrument", nil)
+	assert.NoError(t, err)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", "Bearer test-token"}, authorization)
+}
+
+func TestSignatureAuth(t *testing.T) {
+	type request struct {
+		authorization string
+		uri           *url.URL
+	}
+	var (
+		mu       sync.Mutex
+		requests []request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, request{r.Header.Get("Authorization"), r.URL})
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"access_token":"test-token","refresh_token":"test-refresh"}}`))
+	}))
+	defer server.Close()
+
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	client := &DeribitRestClient{
+		Client:        http.DefaultClient,
+		ClientID:      "test-key",
+		ApiSecret:     "test-secret",
+		BaseURL:       server.URL,
+		Logger:        logger,
+		SignatureAuth: true,
+	}
+
+	_, err := client.GetAccountSummary(&models.GetAccountSummaryParams{Currency: "BTC"})
+	assert.NoError(t, err)
+	_, err = client.GetAuthToken()
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if assert.Len(t, requests, 2) {
+		header := requests[0].authorization
+		assert.Regexp(t, `^deri-hmac-sha256 id=test-key,ts=\d+,sig=[0-9a-f]{64},nonce=\w+$`, header)
+
+		var ts int64
+		var sig, nonce string
+		_, err := fmt.Sscanf(strings.ReplaceAll(header, ",", " "), "deri-hmac-sha256 id=test-key ts=%d sig=%s nonce=%s", &ts, &sig, &nonce)
+		if assert.NoError(t, err) {
+			assert.Equal(t, deribit.RequestSignature("test-secret", ts, nonce, "GET", requests[0].uri.RequestURI(), ""), sig)
+		}
+
+		query := requests[1].uri.Query()
+		assert.Equal(t, "client_signature", query.Get("grant_type"))
+		assert.Empty(t, query.Get("client_secret"))
+		assert.NotEmpty(t, query.Get("signature"))
+	}
+
+	for _, entry := range hook.AllEntries() {
+		assert.NotContains(t, entry.Message, "test-token")
+		assert.NotContains(t, entry.Message, "test-refresh")
+	}
+}
+
+func TestLogRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"access_token":"test-token"}}`))
+	}))
+	defer server.Close()
+
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	client := &DeribitRestClient{
+		Client:    http.DefaultClient,
+		ClientID:  "test-key",
+		ApiSecret: "test-secret",
+		BaseURL:   server.URL,
+		Logger:    logger,
+	}
+
+	_, err := client.GetAuthToken()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, hook.AllEntries())
+	for _, entry := range hook.AllEntries() {
+		assert.NotContains(t, entry.Message, "test-secret")
+		assert.NotContains(t, entry.Message, "test-token")
+	}
+}
+
+func TestContextAndHTTPOptions(t *testing.T) {
+	userAgents := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v2/public/get_book_summary_by_instrument" {
+			userAgents <- r.Header.Get("User-Agent")
+		}
+		if r.URL.Path == "/api/v2/public/ticker" {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewDeribitRestClient(&deribit.Configuration{
+		RestAddr:  server.URL + "/api/v2/",
+		Logger:    logrus.New(),
+		UserAgent: "bot/1.0",
+		Retry:     &deribit.RetryPolicy{MaxAttempts: 1},
+	})
+
+	_, err := client.GetBookSummaryContext(context.Background(), "BTC-PERPETUAL")
+	assert.NoError(t, err)
+	assert.Equal(t, "bot/1.0", <-userAgents)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.GetTickerContext(ctx, "BTC-PERPETUAL")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	client = NewDeribitRestClient(&deribit.Configuration{
+		RestAddr:    server.URL + "/api/v2/",
+		Logger:      logrus.New(),
+		HTTPTimeout: 50 * time.Millisecond,
+		Retry:       &deribit.RetryPolicy{MaxAttempts: 1},
+	})
+	start = time.Now()
+	_, err = client.GetTicker("BTC-PERPETUAL")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
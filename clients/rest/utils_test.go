@@ -2,6 +2,8 @@ package rest
 
 import (
 	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/instruments"
+	"github.com/xingxing/deribit-api/pkg/models"
 	"testing"
 )
 
@@ -23,7 +25,8 @@ func TestRoundToTickSize(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := roundToTickSize(tt.price, tt.tickSize)
+			instrument := models.Instrument{TickSize: tt.tickSize.InexactFloat64()}
+			result := instruments.RoundPrice(instrument, tt.price)
 			if !result.Equal(tt.expected) {
 				t.Errorf("Expected %v, but got %v", tt.expected, result)
 			}
@@ -1,90 +1,160 @@
 package websocket
 
 import (
+	"context"
+
 	"github.com/xingxing/deribit-api/pkg/models"
 )
 
 func (c *DeribitWSClient) GetAnnouncements() (result []models.Announcement, err error) {
-	err = c.Call("public/get_announcements", nil, &result)
+	return c.GetAnnouncementsContext(c.ctx)
+}
+
+func (c *DeribitWSClient) GetAnnouncementsContext(ctx context.Context) (result []models.Announcement, err error) {
+	err = c.CallContext(ctx, "public/get_announcements", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) ChangeSubaccountName(params *models.ChangeSubaccountNameParams) (result string, err error) {
-	err = c.Call("private/change_subaccount_name", params, &result)
+	return c.ChangeSubaccountNameContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) ChangeSubaccountNameContext(ctx context.Context, params *models.ChangeSubaccountNameParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/change_subaccount_name", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CreateSubaccount() (result models.Subaccount, err error) {
-	err = c.Call("private/create_subaccount", nil, &result)
+	return c.CreateSubaccountContext(c.ctx)
+}
+
+func (c *DeribitWSClient) CreateSubaccountContext(ctx context.Context) (result models.Subaccount, err error) {
+	err = c.CallContext(ctx, "private/create_subaccount", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) DisableTfaForSubaccount(params *models.DisableTfaForSubaccountParams) (result string, err error) {
-	err = c.Call("private/disable_tfa_for_subaccount", params, &result)
+	return c.DisableTfaForSubaccountContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) DisableTfaForSubaccountContext(ctx context.Context, params *models.DisableTfaForSubaccountParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/disable_tfa_for_subaccount", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetAccountSummary(params *models.GetAccountSummaryParams) (result models.AccountSummary, err error) {
-	err = c.Call("private/get_account_summary", params, &result)
+	return c.GetAccountSummaryContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetAccountSummaryContext(ctx context.Context, params *models.GetAccountSummaryParams) (result models.AccountSummary, err error) {
+	err = c.CallContext(ctx, "private/get_account_summary", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetEmailLanguage() (result string, err error) {
-	err = c.Call("private/get_email_language", nil, &result)
+	return c.GetEmailLanguageContext(c.ctx)
+}
+
+func (c *DeribitWSClient) GetEmailLanguageContext(ctx context.Context) (result string, err error) {
+	err = c.CallContext(ctx, "private/get_email_language", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetNewAnnouncements() (result []models.Announcement, err error) {
-	err = c.Call("private/get_new_announcements", nil, &result)
+	return c.GetNewAnnouncementsContext(c.ctx)
+}
+
+func (c *DeribitWSClient) GetNewAnnouncementsContext(ctx context.Context) (result []models.Announcement, err error) {
+	err = c.CallContext(ctx, "private/get_new_announcements", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetPosition(params *models.GetPositionParams) (result models.Position, err error) {
-	err = c.Call("private/get_position", params, &result)
+	return c.GetPositionContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetPositionContext(ctx context.Context, params *models.GetPositionParams) (result models.Position, err error) {
+	err = c.CallContext(ctx, "private/get_position", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetPositions(params *models.GetPositionsParams) (result []models.Position, err error) {
-	err = c.Call("private/get_positions", params, &result)
+	return c.GetPositionsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetPositionsContext(ctx context.Context, params *models.GetPositionsParams) (result []models.Position, err error) {
+	err = c.CallContext(ctx, "private/get_positions", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetSubaccounts(params *models.GetSubaccountsParams) (result []models.Subaccount, err error) {
-	err = c.Call("private/get_subaccounts", params, &result)
+	return c.GetSubaccountsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetSubaccountsContext(ctx context.Context, params *models.GetSubaccountsParams) (result []models.Subaccount, err error) {
+	err = c.CallContext(ctx, "private/get_subaccounts", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetSubaccountsDetails(params *models.GetSubaccountsDetailsParams) (result []models.SubaccountsDetails, err error) {
-	err = c.Call("private/get_subaccounts_details", params, &result)
+	return c.GetSubaccountsDetailsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetSubaccountsDetailsContext(ctx context.Context, params *models.GetSubaccountsDetailsParams) (result []models.SubaccountsDetails, err error) {
+	err = c.CallContext(ctx, "private/get_subaccounts_details", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) SetAnnouncementAsRead(params *models.SetAnnouncementAsReadParams) (result string, err error) {
-	err = c.Call("private/set_announcement_as_read", params, &result)
+	return c.SetAnnouncementAsReadContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) SetAnnouncementAsReadContext(ctx context.Context, params *models.SetAnnouncementAsReadParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/set_announcement_as_read", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) SetEmailForSubaccount(params *models.SetEmailForSubaccountParams) (result string, err error) {
-	err = c.Call("private/set_email_for_subaccount", params, &result)
+	return c.SetEmailForSubaccountContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) SetEmailForSubaccountContext(ctx context.Context, params *models.SetEmailForSubaccountParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/set_email_for_subaccount", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) SetEmailLanguage(params *models.SetEmailLanguageParams) (result string, err error) {
-	err = c.Call("private/set_email_language", params, &result)
+	return c.SetEmailLanguageContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) SetEmailLanguageContext(ctx context.Context, params *models.SetEmailLanguageParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/set_email_language", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) SetPasswordForSubaccount(params *models.SetPasswordForSubaccountParams) (result string, err error) {
-	err = c.Call("private/set_password_for_subaccount", params, &result)
+	return c.SetPasswordForSubaccountContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) SetPasswordForSubaccountContext(ctx context.Context, params *models.SetPasswordForSubaccountParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/set_password_for_subaccount", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) ToggleNotificationsFromSubaccount(params *models.ToggleNotificationsFromSubaccountParams) (result string, err error) {
-	err = c.Call("private/toggle_notifications_from_subaccount", params, &result)
+	return c.ToggleNotificationsFromSubaccountContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) ToggleNotificationsFromSubaccountContext(ctx context.Context, params *models.ToggleNotificationsFromSubaccountParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/toggle_notifications_from_subaccount", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) ToggleSubaccountLogin(params *models.ToggleSubaccountLoginParams) (result string, err error) {
-	err = c.Call("private/toggle_subaccount_login", params, &result)
+	return c.ToggleSubaccountLoginContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) ToggleSubaccountLoginContext(ctx context.Context, params *models.ToggleSubaccountLoginParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/toggle_subaccount_login", params, &result)
 	return
 }
@@ -1,28 +1,91 @@
 package websocket
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	websocketmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/deribit"
 	"github.com/xingxing/deribit-api/pkg/models"
 )
 
+// Auth authenticates the connection. With deribit.Configuration.SignatureAuth
+// set it uses the client_signature grant, otherwise client_credentials.
 func (c *DeribitWSClient) Auth(apiKey string, secretKey string) (err error) {
-	params := models.ClientCredentialsParams{
-		GrantType:    "client_credentials",
-		ClientID:     apiKey,
-		ClientSecret: secretKey,
+	return c.AuthContext(c.ctx, apiKey, secretKey)
+}
+
+func (c *DeribitWSClient) AuthContext(ctx context.Context, apiKey string, secretKey string) (err error) {
+	var params interface{}
+	if c.signatureAuth {
+		timestamp := time.Now().UnixMilli()
+		nonce := deribit.Nonce()
+		params = models.ClientSignatureParams{
+			GrantType: "client_signature",
+			ClientID:  apiKey,
+			Timestamp: timestamp,
+			Signature: deribit.Signature(secretKey, timestamp, nonce, ""),
+			Nonce:     nonce,
+		}
+	} else {
+		params = models.ClientCredentialsParams{
+			GrantType:    "client_credentials",
+			ClientID:     apiKey,
+			ClientSecret: secretKey,
+		}
+	}
+	var result models.AuthResponse
+	err = c.CallContext(ctx, "public/auth", params, &result)
+	if err != nil {
+		return
+	}
+	c.setAuth(result)
+	return
+}
+
+// RefreshToken exchanges the stored refresh token for a new access token.
+func (c *DeribitWSClient) RefreshToken() (err error) {
+	return c.RefreshTokenContext(c.ctx)
+}
+
+func (c *DeribitWSClient) RefreshTokenContext(ctx context.Context) (err error) {
+	c.mu.RLock()
+	refresh := c.auth.refresh
+	c.mu.RUnlock()
+	if refresh == "" {
+		return ErrAuthenticationIsRequired
+	}
+	params := models.RefreshTokenParams{
+		GrantType:    "refresh_token",
+		RefreshToken: refresh,
 	}
 	var result models.AuthResponse
-	err = c.Call("public/auth", params, &result)
+	err = c.CallContext(ctx, "public/auth", params, &result)
 	if err != nil {
 		return
 	}
-	c.auth.token = result.AccessToken
-	c.auth.refresh = result.RefreshToken
+	c.setAuth(result)
 	return
 }
 
+// Logout ends the session. Deribit does not answer private/logout; it closes
+// the connection once the request is processed.
 func (c *DeribitWSClient) Logout() (err error) {
-	var result = struct {
-	}{}
-	err = c.Call("public/auth", nil, &result)
+	return c.LogoutContext(c.ctx)
+}
+
+func (c *DeribitWSClient) LogoutContext(ctx context.Context) (err error) {
+	c.mu.RLock()
+	rpcConn := c.rpcConn
+	c.mu.RUnlock()
+	if rpcConn == nil {
+		return errors.New("not connected")
+	}
+	err = rpcConn.Notify(ctx, "private/logout", websocketmodels.EmptyParams)
+	if err != nil {
+		return
+	}
+	c.setAuth(models.AuthResponse{})
 	return
 }
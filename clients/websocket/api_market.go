@@ -1,105 +1,196 @@
 package websocket
 
 import (
+	"context"
+
 	"github.com/xingxing/deribit-api/pkg/models"
 )
 
 func (c *DeribitWSClient) GetBookSummaryByCurrency(params *models.GetBookSummaryByCurrencyParams) (result []models.BookSummary, err error) {
-	err = c.Call("public/get_book_summary_by_currency", params, &result)
+	return c.GetBookSummaryByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetBookSummaryByCurrencyContext(ctx context.Context, params *models.GetBookSummaryByCurrencyParams) (result []models.BookSummary, err error) {
+	err = c.CallContext(ctx, "public/get_book_summary_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetBookSummaryByInstrument(params *models.GetBookSummaryByInstrumentParams) (result []models.BookSummary, err error) {
-	err = c.Call("public/get_book_summary_by_instrument", params, &result)
+	return c.GetBookSummaryByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetBookSummaryByInstrumentContext(ctx context.Context, params *models.GetBookSummaryByInstrumentParams) (result []models.BookSummary, err error) {
+	err = c.CallContext(ctx, "public/get_book_summary_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetContractSize(params *models.GetContractSizeParams) (result models.GetContractSizeResponse, err error) {
-	err = c.Call("public/get_contract_size", params, &result)
+	return c.GetContractSizeContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetContractSizeContext(ctx context.Context, params *models.GetContractSizeParams) (result models.GetContractSizeResponse, err error) {
+	err = c.CallContext(ctx, "public/get_contract_size", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetCurrencies() (result []models.Currency, err error) {
-	err = c.Call("public/get_currencies", nil, &result)
+	return c.GetCurrenciesContext(c.ctx)
+}
+
+func (c *DeribitWSClient) GetCurrenciesContext(ctx context.Context) (result []models.Currency, err error) {
+	err = c.CallContext(ctx, "public/get_currencies", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetFundingChartData(params *models.GetFundingChartDataParams) (result models.GetFundingChartDataResponse, err error) {
-	err = c.Call("public/get_funding_chart_data", params, &result)
+	return c.GetFundingChartDataContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetFundingChartDataContext(ctx context.Context, params *models.GetFundingChartDataParams) (result models.GetFundingChartDataResponse, err error) {
+	err = c.CallContext(ctx, "public/get_funding_chart_data", params, &result)
+	return
+}
+
+func (c *DeribitWSClient) GetFundingRateValue(params *models.GetFundingRateValueParams) (result float64, err error) {
+	return c.GetFundingRateValueContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetFundingRateValueContext(ctx context.Context, params *models.GetFundingRateValueParams) (result float64, err error) {
+	err = c.CallContext(ctx, "public/get_funding_rate_value", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetHistoricalVolatility(params *models.GetHistoricalVolatilityParams) (result models.GetHistoricalVolatilityResponse, err error) {
-	err = c.Call("public/get_historical_volatility", params, &result)
+	return c.GetHistoricalVolatilityContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetHistoricalVolatilityContext(ctx context.Context, params *models.GetHistoricalVolatilityParams) (result models.GetHistoricalVolatilityResponse, err error) {
+	err = c.CallContext(ctx, "public/get_historical_volatility", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetIndex(params *models.GetIndexParams) (result models.GetIndexResponse, err error) {
-	err = c.Call("public/get_index", params, &result)
+	return c.GetIndexContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetIndexContext(ctx context.Context, params *models.GetIndexParams) (result models.GetIndexResponse, err error) {
+	err = c.CallContext(ctx, "public/get_index", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetInstruments(params *models.GetInstrumentsParams) (result []models.Instrument, err error) {
-	err = c.Call("public/get_instruments", params, &result)
+	return c.GetInstrumentsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetInstrumentsContext(ctx context.Context, params *models.GetInstrumentsParams) (result []models.Instrument, err error) {
+	err = c.CallContext(ctx, "public/get_instruments", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetInstrument(params *models.GetInstrumentParams) (result models.Instrument, err error) {
-	err = c.Call("public/get_instrument", params, &result)
+	return c.GetInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetInstrumentContext(ctx context.Context, params *models.GetInstrumentParams) (result models.Instrument, err error) {
+	err = c.CallContext(ctx, "public/get_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastSettlementsByCurrency(params *models.GetLastSettlementsByCurrencyParams) (result models.GetLastSettlementsResponse, err error) {
-	err = c.Call("public/get_last_settlements_by_currency", params, &result)
+	return c.GetLastSettlementsByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastSettlementsByCurrencyContext(ctx context.Context, params *models.GetLastSettlementsByCurrencyParams) (result models.GetLastSettlementsResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_settlements_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastSettlementsByInstrument(params *models.GetLastSettlementsByInstrumentParams) (result models.GetLastSettlementsResponse, err error) {
-	err = c.Call("public/get_last_settlements_by_instrument", params, &result)
+	return c.GetLastSettlementsByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastSettlementsByInstrumentContext(ctx context.Context, params *models.GetLastSettlementsByInstrumentParams) (result models.GetLastSettlementsResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_settlements_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastTradesByCurrency(params *models.GetLastTradesByCurrencyParams) (result models.GetLastTradesResponse, err error) {
-	err = c.Call("public/get_last_trades_by_currency", params, &result)
+	return c.GetLastTradesByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastTradesByCurrencyContext(ctx context.Context, params *models.GetLastTradesByCurrencyParams) (result models.GetLastTradesResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_trades_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastTradesByCurrencyAndTime(params *models.GetLastTradesByCurrencyAndTimeParams) (result models.GetLastTradesResponse, err error) {
-	err = c.Call("public/get_last_trades_by_currency_and_time", params, &result)
+	return c.GetLastTradesByCurrencyAndTimeContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastTradesByCurrencyAndTimeContext(ctx context.Context, params *models.GetLastTradesByCurrencyAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_trades_by_currency_and_time", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastTradesByInstrument(params *models.GetLastTradesByInstrumentParams) (result models.GetLastTradesResponse, err error) {
-	err = c.Call("public/get_last_trades_by_instrument", params, &result)
+	return c.GetLastTradesByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastTradesByInstrumentContext(ctx context.Context, params *models.GetLastTradesByInstrumentParams) (result models.GetLastTradesResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_trades_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetLastTradesByInstrumentAndTime(params *models.GetLastTradesByInstrumentAndTimeParams) (result models.GetLastTradesResponse, err error) {
-	err = c.Call("public/get_last_trades_by_instrument_and_time", params, &result)
+	return c.GetLastTradesByInstrumentAndTimeContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetLastTradesByInstrumentAndTimeContext(ctx context.Context, params *models.GetLastTradesByInstrumentAndTimeParams) (result models.GetLastTradesResponse, err error) {
+	err = c.CallContext(ctx, "public/get_last_trades_by_instrument_and_time", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOrderBook(params *models.GetOrderBookParams) (result models.GetOrderBookResponse, err error) {
-	err = c.Call("public/get_order_book", params, &result)
+	return c.GetOrderBookContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOrderBookContext(ctx context.Context, params *models.GetOrderBookParams) (result models.GetOrderBookResponse, err error) {
+	err = c.CallContext(ctx, "public/get_order_book", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetTradeVolumes() (result models.GetTradeVolumesResponse, err error) {
-	err = c.Call("public/get_trade_volumes", nil, &result)
+	return c.GetTradeVolumesContext(c.ctx)
+}
+
+func (c *DeribitWSClient) GetTradeVolumesContext(ctx context.Context) (result models.GetTradeVolumesResponse, err error) {
+	err = c.CallContext(ctx, "public/get_trade_volumes", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetTradingviewChartData(params *models.GetTradingviewChartDataParams) (result models.GetTradingviewChartDataResponse, err error) {
-	err = c.Call("public/get_tradingview_chart_data", params, &result)
+	return c.GetTradingviewChartDataContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetTradingviewChartDataContext(ctx context.Context, params *models.GetTradingviewChartDataParams) (result models.GetTradingviewChartDataResponse, err error) {
+	err = c.CallContext(ctx, "public/get_tradingview_chart_data", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) Ticker(params *models.TickerParams) (result models.TickerResponse, err error) {
-	err = c.Call("public/ticker", params, &result)
+	return c.TickerContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) TickerContext(ctx context.Context, params *models.TickerParams) (result models.TickerResponse, err error) {
+	err = c.CallContext(ctx, "public/ticker", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetMarkPriceHistory(params *models.GetMarkPriceHistoryParams) (resut models.MarkPriceHistory, err error) {
-	err = c.Call("public/get_mark_price_history", params, &resut)
+	return c.GetMarkPriceHistoryContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetMarkPriceHistoryContext(ctx context.Context, params *models.GetMarkPriceHistoryParams) (resut models.MarkPriceHistory, err error) {
+	err = c.CallContext(ctx, "public/get_mark_price_history", params, &resut)
 	return
 }
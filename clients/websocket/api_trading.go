@@ -1,126 +1,224 @@
 package websocket
 
 import (
+	"context"
+
 	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
 	"github.com/xingxing/deribit-api/pkg/models"
 )
 
 func (c *DeribitWSClient) Buy(params *models.BuyParams) (result models.BuyResponse, err error) {
-	err = c.Call("private/buy", params, &result)
+	return c.BuyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) BuyContext(ctx context.Context, params *models.BuyParams) (result models.BuyResponse, err error) {
+	err = c.CallContext(ctx, "private/buy", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) Sell(params *models.SellParams) (result models.SellResponse, err error) {
-	err = c.Call("private/sell", params, &result)
+	return c.SellContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) SellContext(ctx context.Context, params *models.SellParams) (result models.SellResponse, err error) {
+	err = c.CallContext(ctx, "private/sell", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) Edit(params *models.EditParams) (result models.EditResponse, err error) {
-	err = c.Call("private/edit", params, &result)
+	return c.EditContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) EditContext(ctx context.Context, params *models.EditParams) (result models.EditResponse, err error) {
+	err = c.CallContext(ctx, "private/edit", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) Cancel(params *models.CancelParams) (result models2.Order, err error) {
-	err = c.Call("private/cancel", params, &result)
+	return c.CancelContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelContext(ctx context.Context, params *models.CancelParams) (result models2.Order, err error) {
+	err = c.CallContext(ctx, "private/cancel", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CancelAll() (result string, err error) {
-	err = c.Call("private/cancel_all", nil, &result)
+	return c.CancelAllContext(c.ctx)
+}
+
+func (c *DeribitWSClient) CancelAllContext(ctx context.Context) (result string, err error) {
+	err = c.CallContext(ctx, "private/cancel_all", nil, &result)
 	return
 }
 
 func (c *DeribitWSClient) CancelAllByCurrency(params *models.CancelAllByCurrencyParams) (result string, err error) {
-	err = c.Call("private/cancel_all_by_currency", params, &result)
+	return c.CancelAllByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelAllByCurrencyContext(ctx context.Context, params *models.CancelAllByCurrencyParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/cancel_all_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CancelAllByInstrument(params *models.CancelAllByInstrumentParams) (result string, err error) {
-	err = c.Call("private/cancel_all_by_instrument", params, &result)
+	return c.CancelAllByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelAllByInstrumentContext(ctx context.Context, params *models.CancelAllByInstrumentParams) (result string, err error) {
+	err = c.CallContext(ctx, "private/cancel_all_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CancelByLabel(params *models.CancelByLabelParams) (result int, err error) {
-	err = c.Call("private/cancel_by_label", params, &result)
+	return c.CancelByLabelContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelByLabelContext(ctx context.Context, params *models.CancelByLabelParams) (result int, err error) {
+	err = c.CallContext(ctx, "private/cancel_by_label", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) ClosePosition(params *models.ClosePositionParams) (result models.ClosePositionResponse, err error) {
-	err = c.Call("private/close_position", params, &result)
+	return c.ClosePositionContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) ClosePositionContext(ctx context.Context, params *models.ClosePositionParams) (result models.ClosePositionResponse, err error) {
+	err = c.CallContext(ctx, "private/close_position", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetMargins(params *models.GetMarginsParams) (result models.GetMarginsResponse, err error) {
-	err = c.Call("private/get_margins", params, &result)
+	return c.GetMarginsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetMarginsContext(ctx context.Context, params *models.GetMarginsParams) (result models.GetMarginsResponse, err error) {
+	err = c.CallContext(ctx, "private/get_margins", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOpenOrdersByCurrency(params *models.GetOpenOrdersByCurrencyParams) (result []models2.Order, err error) {
-	err = c.Call("private/get_open_orders_by_currency", params, &result)
+	return c.GetOpenOrdersByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOpenOrdersByCurrencyContext(ctx context.Context, params *models.GetOpenOrdersByCurrencyParams) (result []models2.Order, err error) {
+	err = c.CallContext(ctx, "private/get_open_orders_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOpenOrdersByInstrument(params *models.GetOpenOrdersByInstrumentParams) (result []models2.Order, err error) {
-	err = c.Call("private/get_open_orders_by_instrument", params, &result)
+	return c.GetOpenOrdersByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOpenOrdersByInstrumentContext(ctx context.Context, params *models.GetOpenOrdersByInstrumentParams) (result []models2.Order, err error) {
+	err = c.CallContext(ctx, "private/get_open_orders_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOrderHistoryByCurrency(params *models.GetOrderHistoryByCurrencyParams) (result []models2.Order, err error) {
-	err = c.Call("private/get_order_history_by_currency", params, &result)
+	return c.GetOrderHistoryByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOrderHistoryByCurrencyContext(ctx context.Context, params *models.GetOrderHistoryByCurrencyParams) (result []models2.Order, err error) {
+	err = c.CallContext(ctx, "private/get_order_history_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOrderHistoryByInstrument(params *models.GetOrderHistoryByInstrumentParams) (result []models2.Order, err error) {
-	err = c.Call("private/get_order_history_by_instrument", params, &result)
+	return c.GetOrderHistoryByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOrderHistoryByInstrumentContext(ctx context.Context, params *models.GetOrderHistoryByInstrumentParams) (result []models2.Order, err error) {
+	err = c.CallContext(ctx, "private/get_order_history_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOrderMarginByIDs(params *models.GetOrderMarginByIDsParams) (result models.GetOrderMarginByIDsResponse, err error) {
-	err = c.Call("private/get_order_margin_by_ids", params, &result)
+	return c.GetOrderMarginByIDsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOrderMarginByIDsContext(ctx context.Context, params *models.GetOrderMarginByIDsParams) (result models.GetOrderMarginByIDsResponse, err error) {
+	err = c.CallContext(ctx, "private/get_order_margin_by_ids", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetOrderState(params *models.GetOrderStateParams) (result models2.Order, err error) {
-	err = c.Call("private/get_order_state", params, &result)
+	return c.GetOrderStateContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetOrderStateContext(ctx context.Context, params *models.GetOrderStateParams) (result models2.Order, err error) {
+	err = c.CallContext(ctx, "private/get_order_state", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetStopOrderHistory(params *models.GetStopOrderHistoryParams) (result models.GetStopOrderHistoryResponse, err error) {
-	err = c.Call("private/get_stop_order_history", params, &result)
+	return c.GetStopOrderHistoryContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetStopOrderHistoryContext(ctx context.Context, params *models.GetStopOrderHistoryParams) (result models.GetStopOrderHistoryResponse, err error) {
+	err = c.CallContext(ctx, "private/get_stop_order_history", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetUserTradesByCurrency(params *models.GetUserTradesByCurrencyParams) (result models.GetUserTradesResponse, err error) {
-	err = c.Call("private/get_user_trades_by_currency", params, &result)
+	return c.GetUserTradesByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetUserTradesByCurrencyContext(ctx context.Context, params *models.GetUserTradesByCurrencyParams) (result models.GetUserTradesResponse, err error) {
+	err = c.CallContext(ctx, "private/get_user_trades_by_currency", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetUserTradesByCurrencyAndTime(params *models.GetUserTradesByCurrencyAndTimeParams) (result models.GetUserTradesResponse, err error) {
-	err = c.Call("private/get_user_trades_by_currency_and_time", params, &result)
+	return c.GetUserTradesByCurrencyAndTimeContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetUserTradesByCurrencyAndTimeContext(ctx context.Context, params *models.GetUserTradesByCurrencyAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	err = c.CallContext(ctx, "private/get_user_trades_by_currency_and_time", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetUserTradesByInstrument(params *models.GetUserTradesByInstrumentParams) (result models.GetUserTradesResponse, err error) {
-	err = c.Call("private/get_user_trades_by_instrument", params, &result)
+	return c.GetUserTradesByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetUserTradesByInstrumentContext(ctx context.Context, params *models.GetUserTradesByInstrumentParams) (result models.GetUserTradesResponse, err error) {
+	err = c.CallContext(ctx, "private/get_user_trades_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetUserTradesByInstrumentAndTime(params *models.GetUserTradesByInstrumentAndTimeParams) (result models.GetUserTradesResponse, err error) {
-	err = c.Call("private/get_user_trades_by_instrument_and_time", params, &result)
+	return c.GetUserTradesByInstrumentAndTimeContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetUserTradesByInstrumentAndTimeContext(ctx context.Context, params *models.GetUserTradesByInstrumentAndTimeParams) (result models.GetUserTradesResponse, err error) {
+	err = c.CallContext(ctx, "private/get_user_trades_by_instrument_and_time", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetUserTradesByOrder(params *models.GetUserTradesByOrderParams) (result models.GetUserTradesResponse, err error) {
-	err = c.Call("private/get_user_trades_by_order", params, &result)
+	return c.GetUserTradesByOrderContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetUserTradesByOrderContext(ctx context.Context, params *models.GetUserTradesByOrderParams) (result models.GetUserTradesResponse, err error) {
+	err = c.CallContext(ctx, "private/get_user_trades_by_order", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetSettlementHistoryByInstrument(params *models.GetSettlementHistoryByInstrumentParams) (result models.GetSettlementHistoryResponse, err error) {
-	err = c.Call("private/get_settlement_history_by_instrument", params, &result)
+	return c.GetSettlementHistoryByInstrumentContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetSettlementHistoryByInstrumentContext(ctx context.Context, params *models.GetSettlementHistoryByInstrumentParams) (result models.GetSettlementHistoryResponse, err error) {
+	err = c.CallContext(ctx, "private/get_settlement_history_by_instrument", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetSettlementHistoryByCurrency(params *models.GetSettlementHistoryByCurrencyParams) (result models.GetSettlementHistoryResponse, err error) {
-	err = c.Call("private/get_settlement_history_by_currency", params, &result)
+	return c.GetSettlementHistoryByCurrencyContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetSettlementHistoryByCurrencyContext(ctx context.Context, params *models.GetSettlementHistoryByCurrencyParams) (result models.GetSettlementHistoryResponse, err error) {
+	err = c.CallContext(ctx, "private/get_settlement_history_by_currency", params, &result)
 	return
 }
@@ -1,45 +1,79 @@
 package websocket
 
 import (
+	"context"
+
 	"github.com/xingxing/deribit-api/pkg/models"
 )
 
 func (c *DeribitWSClient) CancelTransferByID(params *models.CancelTransferByIDParams) (result models.Transfer, err error) {
-	err = c.Call("private/cancel_transfer_by_id", params, &result)
+	return c.CancelTransferByIDContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelTransferByIDContext(ctx context.Context, params *models.CancelTransferByIDParams) (result models.Transfer, err error) {
+	err = c.CallContext(ctx, "private/cancel_transfer_by_id", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CancelWithdrawal(params *models.CancelWithdrawalParams) (result models.Withdrawal, err error) {
-	err = c.Call("private/cancel_withdrawal", params, &result)
+	return c.CancelWithdrawalContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CancelWithdrawalContext(ctx context.Context, params *models.CancelWithdrawalParams) (result models.Withdrawal, err error) {
+	err = c.CallContext(ctx, "private/cancel_withdrawal", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) CreateDepositAddress(params *models.CreateDepositAddressParams) (result models.DepositAddress, err error) {
-	err = c.Call("private/create_deposit_address", params, &result)
+	return c.CreateDepositAddressContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) CreateDepositAddressContext(ctx context.Context, params *models.CreateDepositAddressParams) (result models.DepositAddress, err error) {
+	err = c.CallContext(ctx, "private/create_deposit_address", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetCurrentDepositAddress(params *models.GetCurrentDepositAddressParams) (result models.DepositAddress, err error) {
-	err = c.Call("private/get_current_deposit_address", params, &result)
+	return c.GetCurrentDepositAddressContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetCurrentDepositAddressContext(ctx context.Context, params *models.GetCurrentDepositAddressParams) (result models.DepositAddress, err error) {
+	err = c.CallContext(ctx, "private/get_current_deposit_address", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetDeposits(params *models.GetDepositsParams) (result models.GetDepositsResponse, err error) {
-	err = c.Call("private/get_deposits", params, &result)
+	return c.GetDepositsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetDepositsContext(ctx context.Context, params *models.GetDepositsParams) (result models.GetDepositsResponse, err error) {
+	err = c.CallContext(ctx, "private/get_deposits", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetTransfers(params *models.GetTransfersParams) (result models.GetTransfersResponse, err error) {
-	err = c.Call("private/get_transfers", params, &result)
+	return c.GetTransfersContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetTransfersContext(ctx context.Context, params *models.GetTransfersParams) (result models.GetTransfersResponse, err error) {
+	err = c.CallContext(ctx, "private/get_transfers", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) GetWithdrawals(params *models.GetWithdrawalsParams) (result []models.Withdrawal, err error) {
-	err = c.Call("private/get_withdrawals", params, &result)
+	return c.GetWithdrawalsContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) GetWithdrawalsContext(ctx context.Context, params *models.GetWithdrawalsParams) (result []models.Withdrawal, err error) {
+	err = c.CallContext(ctx, "private/get_withdrawals", params, &result)
 	return
 }
 
 func (c *DeribitWSClient) Withdraw(params *models.WithdrawParams) (result models.Withdrawal, err error) {
-	err = c.Call("private/withdraw", params, &result)
+	return c.WithdrawContext(c.ctx, params)
+}
+
+func (c *DeribitWSClient) WithdrawContext(ctx context.Context, params *models.WithdrawParams) (result models.Withdrawal, err error) {
+	err = c.CallContext(ctx, "private/withdraw", params, &result)
 	return
 }
@@ -0,0 +1,121 @@
+package websocket
+
+import (
+	"log"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// refreshAt is the fraction of the token lifetime after which the client
+// refreshes it.
+const refreshAt = 0.8
+
+// setAuth stores the tokens from an auth response and when they expire, and
+// wakes refreshLoop to reschedule. A zero response clears them.
+func (c *DeribitWSClient) setAuth(result models.AuthResponse) {
+	c.mu.Lock()
+	c.auth.token = result.AccessToken
+	c.auth.refresh = result.RefreshToken
+	c.auth.issuedAt = time.Now()
+	c.auth.expiresAt = time.Time{}
+	if result.ExpiresIn > 0 {
+		c.auth.expiresAt = c.auth.issuedAt.Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	c.mu.Unlock()
+
+	select {
+	case c.authChanged <- struct{}{}:
+	default:
+	}
+}
+
+// token returns the current access token
+func (c *DeribitWSClient) token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.auth.token
+}
+
+// TokenExpiresAt returns when the current access token expires, or the zero
+// time when the client is not authenticated.
+func (c *DeribitWSClient) TokenExpiresAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.auth.expiresAt
+}
+
+// nextRefresh returns how long to wait before refreshing the token, and
+// false if there is no token to refresh.
+func (c *DeribitWSClient) nextRefresh() (time.Duration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.auth.token == "" || c.auth.expiresAt.IsZero() {
+		return 0, false
+	}
+	lifetime := c.auth.expiresAt.Sub(c.auth.issuedAt)
+	return time.Until(c.auth.issuedAt.Add(time.Duration(float64(lifetime) * refreshAt))), true
+}
+
+// refreshLoop refreshes the access token before it expires. Without a token
+// it waits for one, so a later Auth call is refreshed too. If the refresh
+// grant fails it reports EventTokenRefreshFailed and falls back to a full
+// re-authentication, backing off between failed rounds.
+func (c *DeribitWSClient) refreshLoop(heartCancel chan struct{}) {
+	defer c.wg.Done()
+
+	failures := 0
+	for {
+		wait, ok := c.nextRefresh()
+		if !ok {
+			select {
+			case <-c.authChanged:
+				failures = 0
+				continue
+			case <-heartCancel:
+				return
+			case <-c.ctx.Done():
+				return
+			}
+		}
+		if failures > 0 {
+			wait = c.reconnectPolicy.Duration(failures - 1)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-c.authChanged:
+			timer.Stop()
+			failures = 0
+			continue
+		case <-heartCancel:
+			timer.Stop()
+			return
+		case <-c.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		err := c.RefreshToken()
+		if err != nil {
+			log.Printf("token refresh error: %v", err)
+			c.emitAuth(EventTokenRefreshFailed, err)
+			if c.apiKey == "" || c.secretKey == "" {
+				failures++
+				continue
+			}
+			err = c.Auth(c.apiKey, c.secretKey)
+		}
+		if err != nil {
+			log.Printf("auth error: %v", err)
+			failures++
+			continue
+		}
+		failures = 0
+		c.emitAuth(EventTokenRefreshed, nil)
+	}
+}
@@ -0,0 +1,135 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type authParams struct {
+	GrantType    string `json:"grant_type"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+	Timestamp    int64  `json:"timestamp"`
+	Signature    string `json:"signature"`
+	Nonce        string `json:"nonce"`
+	Data         string `json:"data"`
+}
+
+func TestClient_TokenRefresh(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/auth", func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p authParams
+		_ = json.Unmarshal(raw, &p)
+		switch {
+		case p.GrantType == "client_credentials":
+			return models.AuthResponse{AccessToken: "t1", RefreshToken: "r1", ExpiresIn: 1}, nil
+		case p.GrantType == "refresh_token" && p.RefreshToken == "r1":
+			return models.AuthResponse{AccessToken: "t2", RefreshToken: "r2", ExpiresIn: 3600}, nil
+		}
+		return nil, &jsonrpc2.Error{Code: deribit.CodeInvalidCredentials, Message: "invalid_credentials"}
+	})
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:    srv.Addr(),
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+	assert.Equal(t, "t1", client.token())
+
+	refreshed := make(chan *AuthEvent, 1)
+	client.On(EventTokenRefreshed, func(e *AuthEvent) { refreshed <- e })
+
+	select {
+	case e := <-refreshed:
+		assert.Equal(t, "t2", client.token())
+		assert.WithinDuration(t, time.Now().Add(time.Hour), e.ExpiresAt, 5*time.Second)
+	case <-time.After(3 * time.Second):
+		t.Fatal("token was not refreshed")
+	}
+}
+
+func TestClient_TokenRefreshAfterAuth(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/auth", func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p authParams
+		_ = json.Unmarshal(raw, &p)
+		if p.GrantType == "refresh_token" {
+			return models.AuthResponse{AccessToken: "t2", RefreshToken: "r2", ExpiresIn: 3600}, nil
+		}
+		return models.AuthResponse{AccessToken: "t1", RefreshToken: "r1", ExpiresIn: 1}, nil
+	})
+	client := newMockClient(t, srv)
+
+	refreshed := make(chan *AuthEvent, 1)
+	client.On(EventTokenRefreshed, func(e *AuthEvent) { refreshed <- e })
+
+	// Authenticate only after the connection is up.
+	assert.NoError(t, client.Auth("key", "secret"))
+	select {
+	case <-refreshed:
+		assert.Equal(t, "t2", client.token())
+	case <-time.After(3 * time.Second):
+		t.Fatal("token was not refreshed")
+	}
+}
+
+func TestClient_TokenRefreshFailed(t *testing.T) {
+	srv := newMockServer(t)
+	var credentialGrants int32
+	srv.Handle("public/auth", func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p authParams
+		_ = json.Unmarshal(raw, &p)
+		if p.GrantType == "refresh_token" {
+			return nil, &jsonrpc2.Error{Code: deribit.CodeInvalidToken, Message: "unauthorized"}
+		}
+		atomic.AddInt32(&credentialGrants, 1)
+		return models.AuthResponse{AccessToken: "t", RefreshToken: "r", ExpiresIn: 1}, nil
+	})
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:    srv.Addr(),
+		ApiKey:    "key",
+		SecretKey: "secret",
+	})
+
+	failed := make(chan *AuthEvent, 4)
+	client.On(EventTokenRefreshFailed, func(e *AuthEvent) { failed <- e })
+
+	select {
+	case e := <-failed:
+		assert.True(t, errors.Is(e.Err, deribit.ErrInvalidToken))
+	case <-time.After(3 * time.Second):
+		t.Fatal("refresh failure not reported")
+	}
+	// The client falls back to the client_credentials grant.
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&credentialGrants) >= 2 }, time.Second, 10*time.Millisecond)
+}
+
+func TestClient_SignatureAuth(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/auth", func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p authParams
+		_ = json.Unmarshal(raw, &p)
+		if p.GrantType != "client_signature" || p.ClientSecret != "" ||
+			p.Signature != deribit.Signature("secret", p.Timestamp, p.Nonce, p.Data) {
+			return nil, &jsonrpc2.Error{Code: deribit.CodeInvalidCredentials, Message: "invalid_credentials"}
+		}
+		return models.AuthResponse{AccessToken: "signed", RefreshToken: "r", ExpiresIn: 900}, nil
+	})
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:        srv.Addr(),
+		ApiKey:        "key",
+		SecretKey:     "secret",
+		SignatureAuth: true,
+	})
+
+	assert.Equal(t, "signed", client.token())
+	assert.Equal(t, StateAuthenticated, client.State())
+}
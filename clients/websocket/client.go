@@ -10,7 +10,6 @@ import (
 	"github.com/xingxing/deribit-api/pkg/models"
 	"log"
 	"net/http"
-	"strings"
 	"sync"
 	"time"
 
@@ -21,55 +20,171 @@ import (
 
 var (
 	ErrAuthenticationIsRequired = errors.New("authentication is required")
+	ErrGaveUp                   = errors.New("gave up connecting")
 )
 
+const closeTimeout = 5 * time.Second
+
 type DeribitWSClient struct {
-	ctx           context.Context
-	addr          string
-	apiKey        string
-	secretKey     string
-	autoReconnect bool
-	debugMode     bool
+	ctx                context.Context
+	cancel             context.CancelFunc
+	addr               string
+	apiKey             string
+	secretKey          string
+	autoReconnect      bool
+	debugMode          bool
+	unsubscribeOnClose bool
+	logoutOnClose      bool
+	reconnectPolicy    *deribit.ReconnectPolicy
+	retryPolicy        *deribit.RetryPolicy
+	rateLimiter        deribit.RateLimiter
+	heartbeatInterval  time.Duration
+	// httpClient and httpHeader carry the WebSocket handshake.
+	httpClient *http.Client
+	httpHeader http.Header
 
 	conn        *websocket.Conn
 	rpcConn     *jsonrpc2.Conn
 	mu          sync.RWMutex
 	heartCancel chan struct{}
 	isConnected bool
-
-	auth struct {
-		token   string
-		refresh string
+	state       ConnectionState
+	wg          sync.WaitGroup
+	closeOnce   sync.Once
+
+	signatureAuth bool
+	auth          struct {
+		token     string
+		refresh   string
+		issuedAt  time.Time
+		expiresAt time.Time
 	}
-
-	subscriptions    []string
-	subscriptionsMap map[string]struct{}
-
-	emitter *emission.Emitter
+	// authChanged is signalled whenever the tokens are stored or cleared.
+	authChanged chan struct{}
+
+	subs        *subscriptionManager
+	subscribeMu sync.Mutex
+
+	emitter    *emission.Emitter
+	handlers   *handlerRegistry
+	dispatcher *dispatcher
+	clock      *clock
+	// disconnectErr is the cause of the next StateDisconnected, if known.
+	disconnectErr error
 }
 
-func NewDeribitWsClient(cfg *deribit.Configuration) *DeribitWSClient {
-	ctx := cfg.Ctx
-	if ctx == nil {
-		ctx = context.Background()
+// Dial connects to cfg.WsAddr and returns a ready client. ctx and
+// cfg.Reconnect.DialTimeout bound the initial connection attempts only; the
+// client stays up until Close is called or cfg.Ctx is done.
+func Dial(ctx context.Context, cfg *deribit.Configuration) (*DeribitWSClient, error) {
+	parent := cfg.Ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	clientCtx, cancel := context.WithCancel(parent)
+	reconnectPolicy := cfg.Reconnect
+	if reconnectPolicy == nil {
+		reconnectPolicy = deribit.DefaultReconnectPolicy()
+	}
+	retryPolicy := cfg.Retry
+	if retryPolicy == nil {
+		retryPolicy = deribit.DefaultRetryPolicy()
+	}
+	heartbeatInterval := cfg.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = defaultHeartbeatInterval
 	}
 	client := &DeribitWSClient{
-		ctx:              ctx,
-		addr:             cfg.WsAddr,
-		apiKey:           cfg.ApiKey,
-		secretKey:        cfg.SecretKey,
-		autoReconnect:    cfg.AutoReconnect,
-		debugMode:        cfg.DebugMode,
-		subscriptionsMap: make(map[string]struct{}),
-		emitter:          emission.NewEmitter(),
+		ctx:                clientCtx,
+		cancel:             cancel,
+		addr:               cfg.WsAddr,
+		apiKey:             cfg.ApiKey,
+		secretKey:          cfg.SecretKey,
+		autoReconnect:      cfg.AutoReconnect,
+		debugMode:          cfg.DebugMode,
+		unsubscribeOnClose: cfg.UnsubscribeOnClose,
+		logoutOnClose:      cfg.LogoutOnClose,
+		reconnectPolicy:    reconnectPolicy,
+		retryPolicy:        retryPolicy,
+		rateLimiter:        cfg.RateLimiter,
+		heartbeatInterval:  heartbeatInterval,
+		httpClient:         cfg.HTTPClient(),
+		httpHeader:         cfg.HTTPHeader(),
+		signatureAuth:      cfg.SignatureAuth,
+		authChanged:        make(chan struct{}, 1),
+		subs:               newSubscriptionManager(),
+		emitter:            emission.NewEmitter(),
+		handlers:           newHandlerRegistry(),
+		clock:              newClock(),
+	}
+	client.dispatcher = newDispatcher(cfg.Dispatch, client.subscriptionsProcess)
+	if err := client.start(ctx, reconnectPolicy.InitialMaxElapsedTime()); err != nil {
+		cancel()
+		client.wg.Wait()
+		_ = client.closeConn()
+		client.dispatcher.stop()
+		return nil, err
 	}
-	err := client.start()
+	return client, nil
+}
+
+// NewDeribitWsClient connects like Dial but exits the process if the
+// connection cannot be established.
+//
+// Deprecated: use Dial, which returns the error instead.
+func NewDeribitWsClient(cfg *deribit.Configuration) *DeribitWSClient {
+	client, err := Dial(context.Background(), cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return client
 }
 
+// Close shuts the client down. It stops the heartbeat and reconnect loops,
+// unsubscribes and logs out if the configuration asks for it, closes the
+// connection and discards undelivered notifications. Close is safe to call
+// more than once.
+func (c *DeribitWSClient) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		if c.IsConnected() {
+			ctx, cancel := context.WithTimeout(c.ctx, closeTimeout)
+			if c.unsubscribeOnClose {
+				if uErr := c.UnsubscribeAllContext(ctx); uErr != nil {
+					log.Printf("unsubscribe error: %v", uErr)
+				}
+			}
+			if c.logoutOnClose && c.token() != "" {
+				if lErr := c.LogoutContext(ctx); lErr != nil {
+					log.Printf("logout error: %v", lErr)
+				}
+			}
+			cancel()
+		}
+
+		c.cancel()
+		c.wg.Wait()
+		err = c.closeConn()
+		c.dispatcher.stop()
+	})
+	return err
+}
+
+// closeConn closes the current JSON-RPC connection and its stream.
+func (c *DeribitWSClient) closeConn() error {
+	c.setIsConnected(false)
+	c.mu.RLock()
+	rpcConn := c.rpcConn
+	c.mu.RUnlock()
+	if rpcConn == nil {
+		return nil
+	}
+	if err := rpcConn.Close(); err != nil && !errors.Is(err, jsonrpc2.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
 // setIsConnected sets state for isConnoected
 func (c *DeribitWSClient) setIsConnected(state bool) {
 	c.mu.Lock()
@@ -78,6 +193,24 @@ func (c *DeribitWSClient) setIsConnected(state bool) {
 	c.isConnected = state
 }
 
+// setDisconnectErr records why the current connection is being closed
+func (c *DeribitWSClient) setDisconnectErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.disconnectErr = err
+}
+
+// takeDisconnectErr returns and clears the recorded disconnect cause
+func (c *DeribitWSClient) takeDisconnectErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.disconnectErr
+	c.disconnectErr = nil
+	return err
+}
+
 // IsConnected returns the WebSocket connection state
 func (c *DeribitWSClient) IsConnected() bool {
 	c.mu.RLock()
@@ -86,136 +219,202 @@ func (c *DeribitWSClient) IsConnected() bool {
 	return c.isConnected
 }
 
-func (c *DeribitWSClient) Subscribe(channels []string) {
-	c.subscriptions = append(c.subscriptions, channels...)
-	c.subscribe(channels)
-}
-
-func (c *DeribitWSClient) subscribe(channels []string) {
-	var publicChannels []string
-	var privateChannels []string
-
-	for _, v := range c.subscriptions {
-		if _, ok := c.subscriptionsMap[v]; ok {
-			continue
+// start connects, retrying according to the reconnect policy, until an
+// attempt succeeds, ctx is done or maxElapsed (if positive) is exceeded.
+func (c *DeribitWSClient) start(ctx context.Context, maxElapsed time.Duration) error {
+	begin := time.Now()
+	for attempt := 1; ; attempt++ {
+		c.setState(StateConnecting, attempt, nil)
+		err := c.connectOnce(ctx)
+		if err == nil {
+			return nil
 		}
-		if strings.HasPrefix(v, "user.") {
-			privateChannels = append(privateChannels, v)
-		} else {
-			publicChannels = append(publicChannels, v)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
-	}
+		log.Printf("connect error: %v", err)
 
-	if len(publicChannels) > 0 {
-		_, err := c.PublicSubscribe(&models.SubscribeParams{
-			Channels: publicChannels,
-		})
-		if err != nil {
-			return
+		delay := c.reconnectPolicy.Duration(attempt - 1)
+		if maxElapsed > 0 && time.Since(begin)+delay > maxElapsed {
+			err = fmt.Errorf("%w after %d attempts: %v", ErrGaveUp, attempt, err)
+			c.setState(StateGaveUp, attempt, err)
+			return err
 		}
-	}
-	if len(privateChannels) > 0 {
-		_, err := c.PrivateSubscribe(&models.SubscribeParams{
-			Channels: privateChannels,
-		})
-		if err != nil {
-			return
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
-
-	allChannels := append(publicChannels, privateChannels...)
-	for _, v := range allChannels {
-		c.subscriptionsMap[v] = struct{}{}
-	}
 }
 
-func (c *DeribitWSClient) start() error {
+// connectOnce dials, authenticates, restores subscriptions and starts the
+// heartbeat and connection watcher for the new connection.
+func (c *DeribitWSClient) connectOnce(ctx context.Context) error {
 	c.setIsConnected(false)
-	c.subscriptionsMap = make(map[string]struct{})
-	c.conn = nil
-	c.rpcConn = nil
-	c.heartCancel = make(chan struct{})
-
-	for i := 0; i < deribit.MaxTryTimes; i++ {
-		conn, _, err := c.connect()
-		if err != nil {
-			log.Println(err)
-			tm := (i + 1) * 5
-			log.Printf("Sleep %vs", tm)
-			time.Sleep(time.Duration(tm) * time.Second)
-			continue
-		}
-		c.conn = conn
-		break
-	}
+	c.subs.reset()
+	heartCancel := make(chan struct{})
 
-	if c.conn == nil {
-		return errors.New("connect fail")
+	conn, _, err := c.connect(ctx)
+	if err != nil {
+		return err
 	}
 
-	// Create a new object stream with the websocket connection
-	stream := websocketmodels.NewObjectStream(c.conn)
+	// Create a new object stream with the websocket connection; every read
+	// feeds the heartbeat watchdog and the call timings
+	stream := websocketmodels.NewObjectStreamWithHook(conn, c.clock.observe)
+	c.clock.touch()
 
 	// Initialize the JSON-RPC connection with the stream
-	c.rpcConn = jsonrpc2.NewConn(c.ctx, stream, c)
+	rpcConn := jsonrpc2.NewConn(c.ctx, stream, c)
+
+	c.mu.Lock()
+	c.conn = conn
+	c.rpcConn = rpcConn
+	c.heartCancel = heartCancel
+	c.mu.Unlock()
 
 	c.setIsConnected(true)
+	c.setState(StateConnected, 0, nil)
 
 	// Authenticate if credentials are provided
 	if c.apiKey != "" && c.secretKey != "" {
 		if err := c.Auth(c.apiKey, c.secretKey); err != nil {
 			log.Printf("auth error: %v", err)
+		} else {
+			c.setState(StateAuthenticated, 0, nil)
 		}
 	}
 
-	// Subscribe to channels
-	c.subscribe(c.subscriptions)
+	// Restore subscriptions; failed channels are retried by resubscribeLoop
+	if len(c.subs.unconfirmed()) > 0 {
+		if err := c.subscribePending(c.ctx); err != nil {
+			log.Printf("subscribe error: %v", err)
+		}
+		c.setState(StateResubscribed, 0, nil)
+	}
 
 	// Set heartbeat
-	_, err := c.SetHeartbeat(&models.SetHeartbeatParams{Interval: 30})
+	_, err = c.SetHeartbeat(&models.SetHeartbeatParams{Interval: c.heartbeatInterval.Seconds()})
 	if err != nil {
+		c.setIsConnected(false)
+		_ = rpcConn.Close()
 		return err
 	}
-
-	// Start reconnection handler if enabled
-	if c.autoReconnect {
-		go c.reconnect()
+	if err := c.syncClock(c.ctx); err != nil {
+		log.Printf("clock sync error: %v", err)
 	}
 
-	// Start heartbeat routine
-	go c.heartbeat()
+	// Watch the connection and start the heartbeat, token refresh and
+	// subscription retry routines
+	c.wg.Add(4)
+	go c.watch(rpcConn, heartCancel)
+	go c.heartbeat(rpcConn, heartCancel)
+	go c.refreshLoop(heartCancel)
+	go c.resubscribeLoop(heartCancel)
 
 	return nil
 }
 
 // Call issues JSONRPC v2 calls
 func (c *DeribitWSClient) Call(method string, params interface{}, result interface{}) (err error) {
+	return c.CallContext(c.ctx, method, params, result)
+}
+
+// CallContext issues JSONRPC v2 calls bound to ctx. If ctx is done before the
+// response arrives, the call returns ctx.Err() and the late response is
+// discarded; the connection itself stays open. Idempotent methods that fail
+// transiently are retried under Configuration.Retry, and every attempt waits
+// for Configuration.RateLimiter.
+func (c *DeribitWSClient) CallContext(ctx context.Context, method string, params interface{}, result interface{}) error {
+	return c.retryPolicy.Do(ctx, method, func() error {
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.Wait(ctx, method); err != nil {
+				return deribit.Permanent(err)
+			}
+		}
+		_, err := c.call(ctx, method, params, result)
+		if c.rateLimiter != nil {
+			c.rateLimiter.Observe(method, err)
+		}
+		if errors.Is(err, ErrAuthenticationIsRequired) {
+			return deribit.Permanent(err)
+		}
+		return err
+	})
+}
+
+// call implements CallContext and also returns the timing of the call.
+func (c *DeribitWSClient) call(ctx context.Context, method string, params interface{}, result interface{}) (timing callTiming, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
 
-	if !c.IsConnected() {
-		return errors.New("not connected")
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	c.mu.RLock()
+	rpcConn, connected := c.rpcConn, c.isConnected
+	c.mu.RUnlock()
+	if !connected {
+		err = errors.New("not connected")
+		return
 	}
 	if params == nil {
 		params = websocketmodels.EmptyParams
 	}
 
 	if token, ok := params.(websocketmodels.PrivateParams); ok {
-		if c.auth.token == "" {
-			return ErrAuthenticationIsRequired
+		accessToken := c.token()
+		if accessToken == "" {
+			err = ErrAuthenticationIsRequired
+			return
 		}
-		token.SetToken(c.auth.token)
+		token.SetToken(accessToken)
+	}
+
+	// DispatchCall registers the request as pending and writes it; Wait
+	// returns early on ctx.Done(). The buffered reply channel owned by the
+	// pending call absorbs a response that arrives after cancellation, so
+	// the read loop never blocks on an abandoned request.
+	id := c.clock.nextID()
+	c.clock.begin(id)
+	call, err := rpcConn.DispatchCall(ctx, method, params, jsonrpc2.PickID(id))
+	if err != nil {
+		c.clock.finish(id, false)
+		return
 	}
+	err = toAPIError(call.Wait(ctx, result))
+	_, isAPIError := deribit.AsAPIError(err)
+	timing = c.clock.finish(id, err == nil || isAPIError)
+	return
+}
 
-	return c.rpcConn.Call(c.ctx, method, params, result)
+// toAPIError converts a JSON-RPC error reply into a *deribit.APIError and
+// passes any other error through unchanged.
+func toAPIError(err error) error {
+	var rpcErr *jsonrpc2.Error
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	apiErr := &deribit.APIError{
+		Code:    int(rpcErr.Code),
+		Message: rpcErr.Message,
+	}
+	if rpcErr.Data != nil {
+		apiErr.Data = *rpcErr.Data
+	}
+	return apiErr
 }
 
 // Handle implements jsonrpc2.Handler
 func (c *DeribitWSClient) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) {
-	if req.Method == "subscription" {
+	switch req.Method {
+	case "heartbeat":
+		c.answerHeartbeat(ctx, conn, req.Params)
+	case "subscription":
 		// update events
 		if req.Params != nil && len(*req.Params) > 0 {
 			var event websocketmodels.Event
@@ -223,47 +422,47 @@ func (c *DeribitWSClient) Handle(ctx context.Context, conn *jsonrpc2.Conn, req *
 				//c.setError(err)
 				return
 			}
-			c.subscriptionsProcess(&event)
+			c.dispatcher.enqueue(&event)
 		}
 	}
 }
 
-func (c *DeribitWSClient) heartbeat() {
-	t := time.NewTicker(3 * time.Second)
-	for {
-		select {
-		case <-t.C:
-			_, err := c.Test()
-			if err != nil {
-				return
-			}
-		case <-c.heartCancel:
-			return
-		}
-	}
-}
+// watch waits for rpcConn to drop, reports the disconnect and, if enabled,
+// reconnects. Errors that end the reconnection are reported through
+// StateGaveUp and the policy's OnGiveUp callback.
+func (c *DeribitWSClient) watch(rpcConn *jsonrpc2.Conn, heartCancel chan struct{}) {
+	defer c.wg.Done()
 
-func (c *DeribitWSClient) reconnect() {
-	notify := c.rpcConn.DisconnectNotify()
-	<-notify
+	select {
+	case <-rpcConn.DisconnectNotify():
+	case <-c.ctx.Done():
+		return
+	}
 	c.setIsConnected(false)
+	close(heartCancel)
 
+	if c.ctx.Err() != nil {
+		return
+	}
+	c.setState(StateDisconnected, 0, c.takeDisconnectErr())
+	if !c.autoReconnect {
+		return
+	}
 	log.Println("disconnect, reconnect...")
 
-	close(c.heartCancel)
-
-	time.Sleep(1 * time.Second)
-
-	err := c.start()
-	if err != nil {
-		return
+	err := c.start(c.ctx, c.reconnectPolicy.MaxElapsedTime)
+	if err != nil && c.ctx.Err() == nil && c.reconnectPolicy.OnGiveUp != nil {
+		c.reconnectPolicy.OnGiveUp(err)
 	}
 }
 
-func (c *DeribitWSClient) connect() (*websocket.Conn, *http.Response, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (c *DeribitWSClient) connect(ctx context.Context) (*websocket.Conn, *http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
-	conn, resp, err := websocket.Dial(ctx, c.addr, &websocket.DialOptions{})
+	conn, resp, err := websocket.Dial(ctx, c.addr, &websocket.DialOptions{
+		HTTPClient: c.httpClient,
+		HTTPHeader: c.httpHeader,
+	})
 	if err == nil {
 		conn.SetReadLimit(32768 * 64)
 	}
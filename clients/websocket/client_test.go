@@ -1,13 +1,20 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	websocketmodels "github.com/xingxing/deribit-api/clients/websocket/models"
 	"github.com/xingxing/deribit-api/pkg/deribit"
 	"github.com/xingxing/deribit-api/pkg/models"
 
+	"github.com/sourcegraph/jsonrpc2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,6 +149,253 @@ func TestClient_Buy(t *testing.T) {
 	t.Logf("%#v", result)
 }
 
+func TestClient_CallContextCancel(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Delay("public/get_time", 500*time.Millisecond)
+	srv.Handle("public/get_time", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return 1700000000000, nil
+	})
+	client := newMockClient(t, srv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var tm int64
+	err := client.CallContext(ctx, "public/get_time", nil, &tm)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// The connection survives the abandoned call.
+	assert.True(t, client.IsConnected())
+	var result models.TestResponse
+	err = client.CallContext(context.Background(), "public/test", nil, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", result.Version)
+}
+
+func TestClient_CallContextAlreadyCancelled(t *testing.T) {
+	srv := newMockServer(t)
+	client := newMockClient(t, srv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.GetInstrumentsContext(ctx, &models.GetInstrumentsParams{Currency: "BTC"})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, 0, srv.Calls("public/get_instruments"))
+}
+
+func TestClient_APIError(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("private/buy", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		data := json.RawMessage(`{"param":"price"}`)
+		return nil, &jsonrpc2.Error{Code: 10007, Message: "price_too_high", Data: &data}
+	})
+	client := newMockClient(t, srv)
+
+	_, err := client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Price: 1e6, Type: "limit"})
+	assert.True(t, errors.Is(err, deribit.ErrPriceTooHigh))
+
+	apiErr, ok := deribit.AsAPIError(err)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"param":"price"}`, string(apiErr.Data))
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	srv := newMockServer(t)
+	addr := srv.Addr()
+	srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	client, err := Dial(ctx, &deribit.Configuration{WsAddr: addr})
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestDial_GivesUp(t *testing.T) {
+	srv := newMockServer(t)
+	addr := srv.Addr()
+	srv.Close()
+
+	client, err := Dial(context.Background(), &deribit.Configuration{
+		WsAddr: addr,
+		Reconnect: &deribit.ReconnectPolicy{
+			Backoff:     deribit.Backoff{InitialInterval: 10 * time.Millisecond, Multiplier: 2},
+			DialTimeout: 100 * time.Millisecond,
+		},
+	})
+	assert.Nil(t, client)
+	assert.True(t, errors.Is(err, ErrGaveUp))
+}
+
+type recordingTransport struct {
+	mu         sync.Mutex
+	userAgents []string
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	r.userAgents = append(r.userAgents, req.Header.Get("User-Agent"))
+	r.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDial_HTTPOptions(t *testing.T) {
+	srv := newMockServer(t)
+	transport := &recordingTransport{}
+	client, err := Dial(context.Background(), &deribit.Configuration{
+		WsAddr:    srv.Addr(),
+		Transport: transport,
+		UserAgent: "bot/1.0",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Close()
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	assert.Equal(t, []string{"bot/1.0"}, transport.userAgents)
+}
+
+func TestClient_Close(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/auth", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return models.AuthResponse{AccessToken: "token", RefreshToken: "refresh", ExpiresIn: 900}, nil
+	})
+	srv.Handle("public/unsubscribe_all", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return "ok", nil
+	})
+	srv.Handle("private/unsubscribe_all", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return "ok", nil
+	})
+	client, err := Dial(context.Background(), &deribit.Configuration{
+		WsAddr:             srv.Addr(),
+		ApiKey:             "key",
+		SecretKey:          "secret",
+		AutoReconnect:      true,
+		UnsubscribeOnClose: true,
+		LogoutOnClose:      true,
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.Close())
+	assert.NoError(t, client.Close())
+	assert.False(t, client.IsConnected())
+	assert.Equal(t, 1, srv.Calls("public/unsubscribe_all"))
+	assert.Equal(t, 1, srv.Calls("private/unsubscribe_all"))
+	assert.Eventually(t, func() bool { return srv.Calls("private/logout") == 1 }, time.Second, 10*time.Millisecond)
+
+	_, err = client.GetTime()
+	assert.Error(t, err)
+}
+
+func TestClient_CloseNoGoroutineLeak(t *testing.T) {
+	srv := newMockServer(t)
+	cfg := &deribit.Configuration{WsAddr: srv.Addr(), AutoReconnect: true}
+
+	// Warm up the server and HTTP machinery before taking the baseline.
+	client, err := Dial(context.Background(), cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+	time.Sleep(50 * time.Millisecond)
+	baseline := runtime.NumGoroutine()
+
+	for i := 0; i < 50; i++ {
+		client, err := Dial(context.Background(), cfg)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = client.Test()
+		assert.NoError(t, err)
+		assert.NoError(t, client.Close())
+	}
+
+	// Poll on this goroutine: assert.Eventually runs its condition on an
+	// extra goroutine that would count against the baseline.
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline)
+}
+
+func recordStates(client *DeribitWSClient, states ...ConnectionState) chan ConnectionState {
+	ch := make(chan ConnectionState, 64)
+	for _, state := range states {
+		client.On(state, func(e *ConnectionEvent) { ch <- e.State })
+	}
+	return ch
+}
+
+func waitState(t *testing.T, ch chan ConnectionState, want ConnectionState) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-ch:
+			if got == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("state %s not reached", want)
+		}
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/subscribe", func(params json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p models.SubscribeParams
+		_ = json.Unmarshal(params, &p)
+		return p.Channels, nil
+	})
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:        srv.Addr(),
+		AutoReconnect: true,
+		Reconnect: &deribit.ReconnectPolicy{
+			Backoff: deribit.Backoff{InitialInterval: 10 * time.Millisecond, Multiplier: 2},
+		},
+	})
+	client.Subscribe([]string{"ticker.BTC-PERPETUAL.raw"})
+	states := recordStates(client, StateDisconnected, StateConnecting, StateConnected, StateResubscribed)
+
+	srv.DropConnections()
+
+	waitState(t, states, StateDisconnected)
+	waitState(t, states, StateConnecting)
+	waitState(t, states, StateConnected)
+	waitState(t, states, StateResubscribed)
+	assert.Equal(t, 2, srv.Calls("public/subscribe"))
+	assert.Eventually(t, client.IsConnected, time.Second, 10*time.Millisecond)
+}
+
+func TestClient_ReconnectGivesUp(t *testing.T) {
+	srv := newMockServer(t)
+	gaveUp := make(chan error, 1)
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:        srv.Addr(),
+		AutoReconnect: true,
+		Reconnect: &deribit.ReconnectPolicy{
+			Backoff:        deribit.Backoff{InitialInterval: 10 * time.Millisecond, Multiplier: 2},
+			MaxElapsedTime: 100 * time.Millisecond,
+			OnGiveUp:       func(err error) { gaveUp <- err },
+		},
+	})
+	states := recordStates(client, StateGaveUp)
+
+	srv.Close()
+	srv.DropConnections()
+
+	waitState(t, states, StateGaveUp)
+	select {
+	case err := <-gaveUp:
+		assert.True(t, errors.Is(err, ErrGaveUp))
+	case <-time.After(time.Second):
+		t.Fatal("OnGiveUp not called")
+	}
+	assert.Equal(t, StateGaveUp, client.State())
+	assert.False(t, client.IsConnected())
+}
+
 func TestJsonOmitempty(t *testing.T) {
 	params := &models.BuyParams{
 		InstrumentName: "BTC-PERPETUAL",
@@ -0,0 +1,213 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/sourcegraph/jsonrpc2"
+	websocketmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+)
+
+// ErrHeartbeatTimeout is reported with StateDisconnected when the server
+// sent nothing for two heartbeat intervals.
+var ErrHeartbeatTimeout = errors.New("heartbeat timeout")
+
+const defaultHeartbeatInterval = 30 * time.Second
+
+var usInKey = []byte(`"usIn"`)
+
+// callTiming holds the timestamps of one request. usIn and usOut are the
+// server's receive and send times in microseconds, if it reported them.
+type callTiming struct {
+	sent     time.Time
+	received time.Time
+	usIn     int64
+	usOut    int64
+}
+
+// clock measures call latency and the offset of the server clock. Requests
+// get ids from nextID so that responses can be matched to their timing as
+// they are read off the wire.
+type clock struct {
+	seq      atomic.Uint64
+	lastRead atomic.Int64
+
+	mu      sync.Mutex
+	pending map[uint64]*callTiming
+	latency time.Duration
+	offset  time.Duration
+	synced  bool
+}
+
+func newClock() *clock {
+	return &clock{pending: make(map[uint64]*callTiming)}
+}
+
+func (k *clock) nextID() jsonrpc2.ID {
+	return jsonrpc2.ID{Num: k.seq.Add(1)}
+}
+
+// begin registers a request that is about to be sent.
+func (k *clock) begin(id jsonrpc2.ID) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.pending[id.Num] = &callTiming{sent: time.Now()}
+}
+
+// finish unregisters a request. If a response arrived, its timing is folded
+// into the latency and offset estimates and returned.
+func (k *clock) finish(id jsonrpc2.ID, responded bool) callTiming {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	t := k.pending[id.Num]
+	delete(k.pending, id.Num)
+	if t == nil || !responded {
+		return callTiming{}
+	}
+	if t.received.IsZero() {
+		t.received = time.Now()
+	}
+
+	rtt := t.received.Sub(t.sent)
+	if t.usIn > 0 && t.usOut >= t.usIn {
+		rtt -= time.Duration(t.usOut-t.usIn) * time.Microsecond
+		k.adjustOffset((time.UnixMicro(t.usIn).Sub(t.sent) + time.UnixMicro(t.usOut).Sub(t.received)) / 2)
+	}
+	if k.latency == 0 {
+		k.latency = rtt
+	} else {
+		k.latency += (rtt - k.latency) / 8
+	}
+	return *t
+}
+
+// adjustOffset folds an offset sample into the estimate. Callers hold k.mu.
+func (k *clock) adjustOffset(offset time.Duration) {
+	if !k.synced {
+		k.offset = offset
+		k.synced = true
+		return
+	}
+	k.offset += (offset - k.offset) / 8
+}
+
+// observe is called with every message read from the connection.
+func (k *clock) observe(data []byte) {
+	now := time.Now()
+	k.lastRead.Store(now.UnixNano())
+	if !bytes.Contains(data, usInKey) {
+		return
+	}
+	var resp struct {
+		ID    uint64 `json:"id"`
+		UsIn  int64  `json:"usIn"`
+		UsOut int64  `json:"usOut"`
+	}
+	if err := jsoniter.Unmarshal(data, &resp); err != nil {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if t, ok := k.pending[resp.ID]; ok {
+		t.received = now
+		t.usIn = resp.UsIn
+		t.usOut = resp.UsOut
+	}
+}
+
+// touch marks the connection as alive.
+func (k *clock) touch() {
+	k.lastRead.Store(time.Now().UnixNano())
+}
+
+func (k *clock) sinceLastRead() time.Duration {
+	return time.Since(time.Unix(0, k.lastRead.Load()))
+}
+
+// Latency returns the smoothed round-trip time of recent calls, excluding
+// the server's processing time when the server reports it. It is zero
+// before the first call completes.
+func (c *DeribitWSClient) Latency() time.Duration {
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+
+	return c.clock.latency
+}
+
+// ServerNow returns the current time on the server's clock, estimated from
+// the measured clock offset.
+func (c *DeribitWSClient) ServerNow() time.Time {
+	c.clock.mu.Lock()
+	defer c.clock.mu.Unlock()
+
+	return time.Now().Add(c.clock.offset)
+}
+
+// syncClock seeds the clock offset with public/get_time. Servers that report
+// usIn and usOut refine the offset on every later call.
+func (c *DeribitWSClient) syncClock(ctx context.Context) error {
+	var serverTime int64
+	t, err := c.call(ctx, "public/get_time", nil, &serverTime)
+	if err != nil {
+		return err
+	}
+	if t.usIn == 0 {
+		midpoint := t.sent.Add(t.received.Sub(t.sent) / 2)
+		c.clock.mu.Lock()
+		c.clock.adjustOffset(time.UnixMilli(serverTime).Sub(midpoint))
+		c.clock.mu.Unlock()
+	}
+	return nil
+}
+
+// answerHeartbeat replies to a server heartbeat of type test_request. It
+// runs on the read goroutine, so it sends public/test without waiting for
+// the response.
+func (c *DeribitWSClient) answerHeartbeat(ctx context.Context, conn *jsonrpc2.Conn, params *json.RawMessage) {
+	if params == nil {
+		return
+	}
+	var heartbeat struct {
+		Type string `json:"type"`
+	}
+	if err := jsoniter.Unmarshal(*params, &heartbeat); err != nil || heartbeat.Type != "test_request" {
+		return
+	}
+	if _, err := conn.DispatchCall(ctx, "public/test", websocketmodels.EmptyParams, jsonrpc2.PickID(c.clock.nextID())); err != nil {
+		log.Printf("heartbeat reply error: %v", err)
+	}
+}
+
+// heartbeat closes rpcConn once the server has been silent for two heartbeat
+// intervals, which lets watch reconnect.
+func (c *DeribitWSClient) heartbeat(rpcConn *jsonrpc2.Conn, heartCancel chan struct{}) {
+	defer c.wg.Done()
+
+	t := time.NewTicker(c.heartbeatInterval / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if c.clock.sinceLastRead() > 2*c.heartbeatInterval {
+				log.Printf("no message for %v, closing connection", 2*c.heartbeatInterval)
+				c.setDisconnectErr(ErrHeartbeatTimeout)
+				_ = rpcConn.Close()
+				return
+			}
+		case <-heartCancel:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+func TestClient_HeartbeatTestRequest(t *testing.T) {
+	srv := newMockServer(t)
+	client := newMockClient(t, srv)
+	assert.Equal(t, 0, srv.Calls("public/test"))
+
+	srv.Heartbeat("heartbeat")
+	srv.Heartbeat("test_request")
+	assert.Eventually(t, func() bool { return srv.Calls("public/test") == 1 }, 2*time.Second, 5*time.Millisecond)
+
+	// The reply does not disturb regular calls.
+	result, err := client.Test()
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", result.Version)
+}
+
+func TestClient_HeartbeatTimeout(t *testing.T) {
+	srv := newMockServer(t)
+	client := newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr:            srv.Addr(),
+		HeartbeatInterval: 100 * time.Millisecond,
+	})
+
+	disconnected := make(chan *ConnectionEvent, 1)
+	client.On(StateDisconnected, func(e *ConnectionEvent) { disconnected <- e })
+
+	// Heartbeats keep the connection alive past the deadline.
+	for i := 0; i < 6; i++ {
+		time.Sleep(50 * time.Millisecond)
+		srv.Heartbeat("heartbeat")
+	}
+	assert.True(t, client.IsConnected())
+
+	select {
+	case e := <-disconnected:
+		assert.True(t, errors.Is(e.Err, ErrHeartbeatTimeout))
+	case <-time.After(2 * time.Second):
+		t.Fatal("silent connection was not closed")
+	}
+	assert.False(t, client.IsConnected())
+}
+
+func TestClient_ClockOffset(t *testing.T) {
+	srv := newMockServer(t)
+	srv.SetClock(5*time.Second, true)
+	srv.Delay("public/test", 50*time.Millisecond)
+	client := newMockClient(t, srv)
+
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), client.ServerNow(), 50*time.Millisecond)
+
+	begin := time.Now()
+	_, err := client.Test()
+	assert.NoError(t, err)
+	// The server's processing time between usIn and usOut is not latency.
+	assert.Greater(t, client.Latency(), time.Duration(0))
+	assert.Less(t, client.Latency(), time.Since(begin)-40*time.Millisecond)
+}
+
+func TestClient_ClockOffsetFromGetTime(t *testing.T) {
+	srv := newMockServer(t)
+	srv.SetClock(-3*time.Second, false)
+	client := newMockClient(t, srv)
+
+	assert.WithinDuration(t, time.Now().Add(-3*time.Second), client.ServerNow(), 50*time.Millisecond)
+	assert.Greater(t, client.Latency(), time.Duration(0))
+}
@@ -0,0 +1,269 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+
+	websocketmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+const defaultQueueSize = 1024
+
+// QueueStats describes the notification queue of one channel.
+type QueueStats struct {
+	Channel  string
+	Policy   deribit.OverflowPolicy
+	Capacity int
+	// Depth is the number of events waiting for delivery.
+	Depth int
+	// Delivered counts events handed to the listeners.
+	Delivered uint64
+	// Dropped counts events discarded by OverflowDropOldest or replaced by
+	// OverflowConflate.
+	Dropped uint64
+}
+
+// dispatcher moves subscription events off the jsonrpc2 read goroutine. Each
+// channel gets a bounded queue drained by its own worker, so a slow listener
+// only holds up its own channel.
+type dispatcher struct {
+	cfg     *deribit.DispatchConfig
+	deliver func(*websocketmodels.Event)
+
+	mu     sync.Mutex
+	queues map[string]*eventQueue
+	// removed holds unsubscribed channels, whose late events are dropped
+	// rather than starting a new worker.
+	removed map[string]bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func newDispatcher(cfg *deribit.DispatchConfig, deliver func(*websocketmodels.Event)) *dispatcher {
+	if cfg == nil {
+		cfg = deribit.DefaultDispatchConfig()
+	}
+	return &dispatcher{
+		cfg:     cfg,
+		deliver: deliver,
+		queues:  make(map[string]*eventQueue),
+		removed: make(map[string]bool),
+	}
+}
+
+// enqueue queues event on its channel, starting the channel's worker on
+// first use. Events are discarded once the dispatcher is stopped or the
+// channel is removed.
+func (d *dispatcher) enqueue(event *websocketmodels.Event) {
+	d.mu.Lock()
+	if d.stopped || d.removed[event.Channel] {
+		d.mu.Unlock()
+		return
+	}
+	q, ok := d.queues[event.Channel]
+	if !ok {
+		q = d.newQueue(event.Channel)
+		d.queues[event.Channel] = q
+		d.wg.Add(1)
+		go d.work(q)
+	}
+	d.mu.Unlock()
+
+	q.push(event)
+}
+
+func (d *dispatcher) newQueue(channel string) *eventQueue {
+	policy := d.cfg.Policy
+	if ch, err := channels.Parse(channel); err == nil {
+		policy = d.cfg.PolicyFor(ch.Type)
+	}
+	size := d.cfg.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if policy == deribit.OverflowConflate {
+		size = 1
+	}
+	q := &eventQueue{channel: channel, policy: policy, capacity: size}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (d *dispatcher) work(q *eventQueue) {
+	defer d.wg.Done()
+
+	for {
+		event, ok := q.pop()
+		if !ok {
+			return
+		}
+		d.deliver(event)
+	}
+}
+
+// stop discards queued events, wakes blocked producers and waits for the
+// workers to finish their current delivery.
+func (d *dispatcher) stop() {
+	d.mu.Lock()
+	d.stopped = true
+	queues := make([]*eventQueue, 0, len(d.queues))
+	for _, q := range d.queues {
+		queues = append(queues, q)
+	}
+	d.mu.Unlock()
+
+	for _, q := range queues {
+		q.close()
+	}
+	d.wg.Wait()
+}
+
+// add accepts events of channels again after they were removed.
+func (d *dispatcher) add(channels []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, ch := range channels {
+		delete(d.removed, ch)
+	}
+}
+
+// remove discards the queues of channels, lets their workers exit and
+// drops the channels' events until they are added again. A worker in the
+// middle of a delivery finishes it first.
+func (d *dispatcher) remove(channels []string) {
+	d.mu.Lock()
+	queues := make([]*eventQueue, 0, len(channels))
+	for _, ch := range channels {
+		d.removed[ch] = true
+		if q, ok := d.queues[ch]; ok {
+			queues = append(queues, q)
+			delete(d.queues, ch)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, q := range queues {
+		q.close()
+	}
+}
+
+// removeAll discards every queue, like remove for channels and every
+// channel with a queue.
+func (d *dispatcher) removeAll(channels []string) {
+	d.mu.Lock()
+	queues := d.queues
+	d.queues = make(map[string]*eventQueue)
+	for _, ch := range channels {
+		d.removed[ch] = true
+	}
+	for ch := range queues {
+		d.removed[ch] = true
+	}
+	d.mu.Unlock()
+
+	for _, q := range queues {
+		q.close()
+	}
+}
+
+func (d *dispatcher) stats() []QueueStats {
+	d.mu.Lock()
+	queues := make([]*eventQueue, 0, len(d.queues))
+	for _, q := range d.queues {
+		queues = append(queues, q)
+	}
+	d.mu.Unlock()
+
+	stats := make([]QueueStats, len(queues))
+	for i, q := range queues {
+		stats[i] = q.stats()
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Channel < stats[j].Channel })
+	return stats
+}
+
+// eventQueue is a bounded FIFO with an overflow policy.
+type eventQueue struct {
+	channel  string
+	policy   deribit.OverflowPolicy
+	capacity int
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	events    []*websocketmodels.Event
+	closed    bool
+	delivered uint64
+	dropped   uint64
+}
+
+func (q *eventQueue) push(event *websocketmodels.Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.events) >= q.capacity && !q.closed {
+		switch q.policy {
+		case deribit.OverflowDropOldest, deribit.OverflowConflate:
+			q.events[0] = nil
+			q.events = q.events[1:]
+			q.dropped++
+		default:
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		return
+	}
+	q.events = append(q.events, event)
+	q.cond.Broadcast()
+}
+
+// pop waits for the next event. It returns false once the queue is closed.
+func (q *eventQueue) pop() (*websocketmodels.Event, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.events) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+	event := q.events[0]
+	q.events[0] = nil
+	q.events = q.events[1:]
+	q.delivered++
+	q.cond.Broadcast()
+	return event, true
+}
+
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.events = nil
+	q.cond.Broadcast()
+}
+
+func (q *eventQueue) stats() QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return QueueStats{
+		Channel:   q.channel,
+		Policy:    q.policy,
+		Capacity:  q.capacity,
+		Depth:     len(q.events),
+		Delivered: q.delivered,
+		Dropped:   q.dropped,
+	}
+}
+
+// QueueStats returns the state of every channel's notification queue, sorted
+// by channel. Growing Depth or Dropped means the listeners fall behind.
+func (c *DeribitWSClient) QueueStats() []QueueStats {
+	return c.dispatcher.stats()
+}
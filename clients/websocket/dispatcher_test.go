@@ -0,0 +1,205 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	websocketmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// gatedDeliver records delivered events and blocks until release is closed.
+type gatedDeliver struct {
+	release chan struct{}
+	mu      sync.Mutex
+	got     []string
+}
+
+func (g *gatedDeliver) deliver(event *websocketmodels.Event) {
+	<-g.release
+	g.mu.Lock()
+	g.got = append(g.got, string(event.Data))
+	g.mu.Unlock()
+}
+
+func (g *gatedDeliver) delivered() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.got...)
+}
+
+func event(channel string, i int) *websocketmodels.Event {
+	return &websocketmodels.Event{Channel: channel, Data: []byte(fmt.Sprint(i))}
+}
+
+func waitDepth(t *testing.T, d *dispatcher, depth int) {
+	assert.Eventually(t, func() bool {
+		stats := d.stats()
+		return len(stats) == 1 && stats[0].Depth == depth
+	}, time.Second, time.Millisecond)
+}
+
+func TestDispatcher_DropOldest(t *testing.T) {
+	g := &gatedDeliver{release: make(chan struct{})}
+	d := newDispatcher(&deribit.DispatchConfig{QueueSize: 3, Policy: deribit.OverflowDropOldest}, g.deliver)
+	defer d.stop()
+
+	// The worker takes event 0 and blocks in deliver.
+	d.enqueue(event("book.BTC-PERPETUAL.raw", 0))
+	waitDepth(t, d, 0)
+	for i := 1; i <= 5; i++ {
+		d.enqueue(event("book.BTC-PERPETUAL.raw", i))
+	}
+	stats := d.stats()[0]
+	assert.Equal(t, deribit.OverflowDropOldest, stats.Policy)
+	assert.Equal(t, 3, stats.Depth)
+	assert.Equal(t, uint64(2), stats.Dropped)
+
+	close(g.release)
+	assert.Eventually(t, func() bool { return len(g.delivered()) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"0", "3", "4", "5"}, g.delivered())
+	assert.Equal(t, uint64(4), d.stats()[0].Delivered)
+}
+
+func TestDispatcher_ConflateTicker(t *testing.T) {
+	g := &gatedDeliver{release: make(chan struct{})}
+	d := newDispatcher(nil, g.deliver)
+	defer d.stop()
+
+	d.enqueue(event("ticker.BTC-PERPETUAL.100ms", 0))
+	waitDepth(t, d, 0)
+	for i := 1; i <= 5; i++ {
+		d.enqueue(event("ticker.BTC-PERPETUAL.100ms", i))
+	}
+	stats := d.stats()[0]
+	assert.Equal(t, deribit.OverflowConflate, stats.Policy)
+	assert.Equal(t, 1, stats.Depth)
+	assert.Equal(t, uint64(4), stats.Dropped)
+
+	close(g.release)
+	assert.Eventually(t, func() bool { return len(g.delivered()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"0", "5"}, g.delivered())
+}
+
+func TestDispatcher_Block(t *testing.T) {
+	g := &gatedDeliver{release: make(chan struct{})}
+	d := newDispatcher(&deribit.DispatchConfig{QueueSize: 1}, g.deliver)
+	defer d.stop()
+
+	d.enqueue(event("trades.BTC-PERPETUAL.raw", 0))
+	waitDepth(t, d, 0)
+	d.enqueue(event("trades.BTC-PERPETUAL.raw", 1))
+
+	pushed := make(chan struct{})
+	go func() {
+		d.enqueue(event("trades.BTC-PERPETUAL.raw", 2))
+		close(pushed)
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("enqueue did not block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(g.release)
+	<-pushed
+	assert.Eventually(t, func() bool { return len(g.delivered()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"0", "1", "2"}, g.delivered())
+	assert.Equal(t, uint64(0), d.stats()[0].Dropped)
+}
+
+func TestDispatcher_StopUnblocksProducers(t *testing.T) {
+	g := &gatedDeliver{release: make(chan struct{})}
+	d := newDispatcher(&deribit.DispatchConfig{QueueSize: 1}, g.deliver)
+
+	d.enqueue(event("trades.BTC-PERPETUAL.raw", 0))
+	waitDepth(t, d, 0)
+	d.enqueue(event("trades.BTC-PERPETUAL.raw", 1))
+	pushed := make(chan struct{})
+	go func() {
+		d.enqueue(event("trades.BTC-PERPETUAL.raw", 2))
+		close(pushed)
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		d.stop()
+		close(stopped)
+	}()
+	<-pushed
+	close(g.release)
+	<-stopped
+	assert.Equal(t, []string{"0"}, g.delivered())
+
+	// Events after stop are discarded.
+	d.enqueue(event("trades.BTC-PERPETUAL.raw", 3))
+	assert.Equal(t, 0, d.stats()[0].Depth)
+}
+
+func TestClient_SlowListenerDoesNotBlockCalls(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err := client.OnBookRaw("BTC-PERPETUAL", func(*models.OrderBookRawNotification) { <-release })
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		srv.Notify("book.BTC-PERPETUAL.raw", map[string]interface{}{"change_id": i})
+	}
+	assert.Eventually(t, func() bool {
+		stats := client.QueueStats()
+		return len(stats) == 1 && stats[0].Depth == 9
+	}, 2*time.Second, 5*time.Millisecond)
+
+	result, err := client.Test()
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", result.Version)
+}
+
+func TestClient_UnsubscribeRemovesQueue(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	srv.Handle("public/unsubscribe_all", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return "ok", nil
+	})
+	client := newMockClient(t, srv)
+
+	channels := []string{"ticker.BTC-PERPETUAL.raw", "ticker.ETH-PERPETUAL.raw"}
+	assert.NoError(t, client.Subscribe(channels))
+	for _, ch := range channels {
+		srv.Notify(ch, map[string]interface{}{"timestamp": 1})
+	}
+	assert.Eventually(t, func() bool { return len(client.QueueStats()) == 2 }, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, client.Unsubscribe(channels[:1]))
+	if stats := client.QueueStats(); assert.Len(t, stats, 1) {
+		assert.Equal(t, channels[1], stats[0].Channel)
+	}
+
+	assert.NoError(t, client.UnsubscribeAll())
+	assert.Empty(t, client.QueueStats())
+
+	// Late notifications of unsubscribed channels start no new queue.
+	for _, ch := range channels {
+		srv.Notify(ch, map[string]interface{}{"timestamp": 2})
+	}
+	result, err := client.Test()
+	assert.NoError(t, err)
+	assert.Equal(t, "mock", result.Version)
+	assert.Empty(t, client.QueueStats())
+
+	// Subscribing again accepts them.
+	assert.NoError(t, client.Subscribe(channels[:1]))
+	srv.Notify(channels[0], map[string]interface{}{"timestamp": 3})
+	assert.Eventually(t, func() bool { return len(client.QueueStats()) == 1 }, time.Second, 5*time.Millisecond)
+}
@@ -1,9 +1,70 @@
 package websocket
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/chuckpreslar/emission"
 )
 
+// ConnectionState is a connection lifecycle state. Each state is also an
+// event key: listen with
+//
+//	client.On(websocket.StateDisconnected, func(e *websocket.ConnectionEvent) {})
+type ConnectionState string
+
+const (
+	StateConnecting    ConnectionState = "connecting"
+	StateConnected     ConnectionState = "connected"
+	StateAuthenticated ConnectionState = "authenticated"
+	StateResubscribed  ConnectionState = "resubscribed"
+	StateDisconnected  ConnectionState = "disconnected"
+	StateGaveUp        ConnectionState = "gave_up"
+)
+
+// ConnectionEvent is emitted whenever the connection changes state.
+type ConnectionEvent struct {
+	State ConnectionState
+	// Attempt is the connection attempt number, set for StateConnecting
+	// and StateGaveUp.
+	Attempt int
+	// Err is the error that ended reconnection, set for StateGaveUp, or
+	// the known cause of a StateDisconnected such as ErrHeartbeatTimeout.
+	Err  error
+	Time time.Time
+}
+
+// AuthEventType identifies token lifecycle events. Listen with
+//
+//	client.On(websocket.EventTokenRefreshFailed, func(e *websocket.AuthEvent) {})
+type AuthEventType string
+
+const (
+	EventTokenRefreshed     AuthEventType = "token_refreshed"
+	EventTokenRefreshFailed AuthEventType = "token_refresh_failed"
+)
+
+// AuthEvent is emitted after every automatic token refresh attempt.
+type AuthEvent struct {
+	Type      AuthEventType
+	ExpiresAt time.Time
+	// Err is the refresh error, set for EventTokenRefreshFailed.
+	Err  error
+	Time time.Time
+}
+
+// EventUnhandled is emitted with an *UnhandledEvent for notifications on
+// channels the client has no model for:
+//
+//	client.On(websocket.EventUnhandled, func(e *websocket.UnhandledEvent) {})
+const EventUnhandled = "unhandled"
+
+// UnhandledEvent carries the raw data of an unhandled notification.
+type UnhandledEvent struct {
+	Channel string
+	Data    json.RawMessage
+}
+
 // On adds a listener to a specific event
 func (c *DeribitWSClient) On(event interface{}, listener interface{}) *emission.Emitter {
 	return c.emitter.On(event, listener)
@@ -18,3 +79,35 @@ func (c *DeribitWSClient) Emit(event interface{}, arguments ...interface{}) *emi
 func (c *DeribitWSClient) Off(event interface{}, listener interface{}) *emission.Emitter {
 	return c.emitter.Off(event, listener)
 }
+
+// State returns the current connection state
+func (c *DeribitWSClient) State() ConnectionState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.state
+}
+
+// setState records the new state and emits it as a ConnectionEvent
+func (c *DeribitWSClient) setState(state ConnectionState, attempt int, err error) {
+	c.mu.Lock()
+	c.state = state
+	c.mu.Unlock()
+
+	c.Emit(state, &ConnectionEvent{
+		State:   state,
+		Attempt: attempt,
+		Err:     err,
+		Time:    time.Now(),
+	})
+}
+
+// emitAuth emits an AuthEvent
+func (c *DeribitWSClient) emitAuth(typ AuthEventType, err error) {
+	c.Emit(typ, &AuthEvent{
+		Type:      typ,
+		ExpiresAt: c.TokenExpiresAt(),
+		Err:       err,
+		Time:      time.Now(),
+	})
+}
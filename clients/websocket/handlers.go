@@ -0,0 +1,271 @@
+package websocket
+
+import (
+	"sync"
+
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
+)
+
+// handlerRegistry holds the typed notification handlers per channel. Handlers
+// are plain closures, so dispatch costs a type assertion rather than the
+// reflect.Call the emitter needs.
+type handlerRegistry struct {
+	mu       sync.RWMutex
+	nextID   uint64
+	handlers map[string][]typedHandler
+	// owned holds the channels whose subscription was made by a typed
+	// handler rather than by Subscribe.
+	owned map[string]bool
+}
+
+type typedHandler struct {
+	id uint64
+	fn func(interface{})
+}
+
+func newHandlerRegistry() *handlerRegistry {
+	return &handlerRegistry{
+		handlers: make(map[string][]typedHandler),
+		owned:    make(map[string]bool),
+	}
+}
+
+func (r *handlerRegistry) add(channel string, fn func(interface{})) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	r.handlers[channel] = append(r.handlers[channel], typedHandler{id: r.nextID, fn: fn})
+	return r.nextID
+}
+
+// own records that the typed handlers subscribed to channel.
+func (r *handlerRegistry) own(channel string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.owned[channel] = true
+}
+
+// disown hands channels over to Subscribe callers, so removing the last
+// typed handler leaves them subscribed.
+func (r *handlerRegistry) disown(channels []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, ch := range channels {
+		delete(r.owned, ch)
+	}
+}
+
+// remove drops the handler and reports whether it was the last one on a
+// channel the typed handlers own.
+func (r *handlerRegistry) remove(channel string, id uint64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	handlers := r.handlers[channel]
+	for i, h := range handlers {
+		if h.id == id {
+			// Copy so that a concurrent dispatch keeps a consistent slice.
+			handlers = append(append([]typedHandler(nil), handlers[:i]...), handlers[i+1:]...)
+			break
+		}
+	}
+	if len(handlers) == 0 {
+		owned := r.owned[channel]
+		delete(r.handlers, channel)
+		delete(r.owned, channel)
+		return owned
+	}
+	r.handlers[channel] = handlers
+	return false
+}
+
+func (r *handlerRegistry) dispatch(channel string, notification interface{}) {
+	r.mu.RLock()
+	handlers := r.handlers[channel]
+	r.mu.RUnlock()
+
+	for _, h := range handlers {
+		h.fn(notification)
+	}
+}
+
+// Subscription is a handle for a typed channel handler.
+type Subscription struct {
+	client  *DeribitWSClient
+	channel string
+	id      uint64
+	once    sync.Once
+}
+
+// Channel returns the subscribed channel name.
+func (s *Subscription) Channel() string {
+	return s.channel
+}
+
+// Close removes the handler. When it was the last typed handler on the
+// channel, the client also unsubscribes from the channel, unless the channel
+// was subscribed with Subscribe or has On listeners. Close is safe to call
+// more than once.
+func (s *Subscription) Close() error {
+	var err error
+	s.once.Do(func() {
+		if s.client.handlers.remove(s.channel, s.id) && s.client.emitter.GetListenerCount(s.channel) == 0 {
+			err = s.client.Unsubscribe([]string{s.channel})
+		}
+	})
+	return err
+}
+
+// on registers fn for channel and subscribes to it. If the server rejects
+// the channel, the handler is removed again and the error returned.
+func on[T any](c *DeribitWSClient, channel string, fn func(*T)) (*Subscription, error) {
+	id := c.handlers.add(channel, func(v interface{}) {
+		if notification, ok := v.(*T); ok {
+			fn(notification)
+		}
+	})
+	sub := &Subscription{client: c, channel: channel, id: id}
+	if _, tracked := c.subs.status(channel); !tracked {
+		c.handlers.own(channel)
+	}
+	err := c.subscribe(c.ctx, []string{channel})
+	if status, ok := c.subs.status(channel); err == nil && ok && status.State == SubscriptionFailed {
+		err = status.Err
+	}
+	if err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+// emit delivers a decoded notification to the typed handlers and the
+// emitter listeners of channel.
+func (c *DeribitWSClient) emit(channel string, notification interface{}) {
+	c.handlers.dispatch(channel, notification)
+	c.Emit(channel, notification)
+}
+
+// OnTicker subscribes to ticker.{instrument}.{interval}.
+func (c *DeribitWSClient) OnTicker(instrument string, interval channels.Interval, fn func(*models.TickerNotification)) (*Subscription, error) {
+	return on(c, channels.Ticker(instrument, interval).String(), fn)
+}
+
+// OnBook subscribes to book.{instrument}.{interval} for an aggregated
+// interval such as channels.Interval100ms. Use OnBookRaw for the raw feed.
+func (c *DeribitWSClient) OnBook(instrument string, interval channels.Interval, fn func(*models.OrderBookNotification)) (*Subscription, error) {
+	return on(c, channels.Book(instrument, "", 0, interval).String(), fn)
+}
+
+// OnBookRaw subscribes to book.{instrument}.raw.
+func (c *DeribitWSClient) OnBookRaw(instrument string, fn func(*models.OrderBookRawNotification)) (*Subscription, error) {
+	return on(c, channels.Book(instrument, "", 0, channels.IntervalRaw).String(), fn)
+}
+
+// OnBookGroup subscribes to book.{instrument}.{group}.{depth}.{interval}.
+func (c *DeribitWSClient) OnBookGroup(instrument string, group string, depth int, interval channels.Interval, fn func(*models.OrderBookGroupNotification)) (*Subscription, error) {
+	return on(c, channels.Book(instrument, group, depth, interval).String(), fn)
+}
+
+// OnBookSnapshot subscribes to book.{instrument}.{group}.{depth}.{interval}
+// like OnBookGroup and converts each notification to an orderbook.Snapshot.
+func (c *DeribitWSClient) OnBookSnapshot(instrument string, group string, depth int, interval channels.Interval, fn func(orderbook.Snapshot)) (*Subscription, error) {
+	return c.OnBookGroup(instrument, group, depth, interval, func(n *models.OrderBookGroupNotification) {
+		fn(orderbook.FromGroupNotification(n, group, depth))
+	})
+}
+
+// OnTrades subscribes to trades.{instrument}.{interval}.
+func (c *DeribitWSClient) OnTrades(instrument string, interval channels.Interval, fn func(*models.TradesNotification)) (*Subscription, error) {
+	return on(c, channels.Trades(instrument, interval).String(), fn)
+}
+
+// OnQuote subscribes to quote.{instrument}.
+func (c *DeribitWSClient) OnQuote(instrument string, fn func(*models.QuoteNotification)) (*Subscription, error) {
+	return on(c, channels.Quote(instrument).String(), fn)
+}
+
+// OnPerpetual subscribes to perpetual.{instrument}.{interval}.
+func (c *DeribitWSClient) OnPerpetual(instrument string, interval channels.Interval, fn func(*models.PerpetualNotification)) (*Subscription, error) {
+	return on(c, channels.Perpetual(instrument, interval).String(), fn)
+}
+
+// OnDeribitPriceIndex subscribes to deribit_price_index.{indexName}.
+func (c *DeribitWSClient) OnDeribitPriceIndex(indexName string, fn func(*models.DeribitPriceIndexNotification)) (*Subscription, error) {
+	return on(c, channels.DeribitPriceIndex(indexName).String(), fn)
+}
+
+// OnUserOrders subscribes to user.orders.{instrument}.{interval}.
+func (c *DeribitWSClient) OnUserOrders(instrument string, interval channels.Interval, fn func(*models.UserOrderNotification)) (*Subscription, error) {
+	return on(c, channels.UserOrdersByInstrument(instrument, interval).String(), fn)
+}
+
+// OnUserTrades subscribes to user.trades.{instrument}.{interval}.
+func (c *DeribitWSClient) OnUserTrades(instrument string, interval channels.Interval, fn func(*models.UserTradesNotification)) (*Subscription, error) {
+	return on(c, channels.UserTradesByInstrument(instrument, interval).String(), fn)
+}
+
+// OnUserChanges subscribes to user.changes.{instrument}.{interval}.
+func (c *DeribitWSClient) OnUserChanges(instrument string, interval channels.Interval, fn func(*models.UserChangesNotification)) (*Subscription, error) {
+	return on(c, channels.UserChangesByInstrument(instrument, interval).String(), fn)
+}
+
+// OnUserPortfolio subscribes to user.portfolio.{currency}.
+func (c *DeribitWSClient) OnUserPortfolio(currency string, fn func(*models.PortfolioNotification)) (*Subscription, error) {
+	return on(c, channels.UserPortfolio(currency).String(), fn)
+}
+
+// OnBookDepth subscribes to book.{instrument}.{depth}.{interval}.
+func (c *DeribitWSClient) OnBookDepth(instrument string, depth int, interval channels.Interval, fn func(*models.OrderBookGroupNotification)) (*Subscription, error) {
+	return on(c, channels.BookDepth(instrument, depth, interval).String(), fn)
+}
+
+// OnChartTrades subscribes to chart.trades.{instrument}.{resolution}.
+func (c *DeribitWSClient) OnChartTrades(instrument string, resolution string, fn func(*models.ChartTradesNotification)) (*Subscription, error) {
+	return on(c, channels.ChartTrades(instrument, resolution).String(), fn)
+}
+
+// OnIncrementalTicker subscribes to incremental_ticker.{instrument}.
+func (c *DeribitWSClient) OnIncrementalTicker(instrument string, fn func(*models.IncrementalTickerNotification)) (*Subscription, error) {
+	return on(c, channels.IncrementalTicker(instrument).String(), fn)
+}
+
+// OnInstrumentState subscribes to instrument.state.{kind}.{currency}.
+func (c *DeribitWSClient) OnInstrumentState(kind channels.Kind, currency string, fn func(*models.InstrumentStateNotification)) (*Subscription, error) {
+	return on(c, channels.InstrumentState(kind, currency).String(), fn)
+}
+
+// OnPlatformState subscribes to platform_state.
+func (c *DeribitWSClient) OnPlatformState(fn func(*models.PlatformStateNotification)) (*Subscription, error) {
+	return on(c, channels.PlatformState().String(), fn)
+}
+
+// OnDeribitVolatilityIndex subscribes to deribit_volatility_index.{indexName}.
+func (c *DeribitWSClient) OnDeribitVolatilityIndex(indexName string, fn func(*models.DeribitVolatilityIndexNotification)) (*Subscription, error) {
+	return on(c, channels.DeribitVolatilityIndex(indexName).String(), fn)
+}
+
+// OnUserMMPTrigger subscribes to user.mmp_trigger.{indexName}.
+func (c *DeribitWSClient) OnUserMMPTrigger(indexName string, fn func(*models.UserMMPTriggerNotification)) (*Subscription, error) {
+	return on(c, channels.UserMMPTrigger(indexName).String(), fn)
+}
+
+// OnUserAccessLog subscribes to user.access_log.
+func (c *DeribitWSClient) OnUserAccessLog(fn func(*models.UserAccessLogNotification)) (*Subscription, error) {
+	return on(c, channels.UserAccessLog().String(), fn)
+}
+
+// OnUserLock subscribes to user.lock.
+func (c *DeribitWSClient) OnUserLock(fn func(*models.UserLockNotification)) (*Subscription, error) {
+	return on(c, channels.UserLock().String(), fn)
+}
+
+// OnBlockTradeConfirmations subscribes to block_trade_confirmations.
+func (c *DeribitWSClient) OnBlockTradeConfirmations(fn func(*models.BlockTradeConfirmationNotification)) (*Subscription, error) {
+	return on(c, channels.BlockTradeConfirmations().String(), fn)
+}
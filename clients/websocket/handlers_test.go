@@ -0,0 +1,138 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
+)
+
+func TestTypedHandler_Ticker(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	tickers := make(chan *models.TickerNotification, 1)
+	sub, err := client.OnTicker("BTC-PERPETUAL", "100ms", func(n *models.TickerNotification) { tickers <- n })
+	assert.NoError(t, err)
+	assert.Equal(t, "ticker.BTC-PERPETUAL.100ms", sub.Channel())
+	assert.Equal(t, SubscriptionActive, subscriptionState(client, sub.Channel()))
+
+	srv.Notify(sub.Channel(), map[string]interface{}{"instrument_name": "BTC-PERPETUAL", "last_price": 42000.5})
+	select {
+	case n := <-tickers:
+		assert.Equal(t, "BTC-PERPETUAL", n.InstrumentName)
+		assert.Equal(t, 42000.5, n.LastPrice)
+	case <-time.After(2 * time.Second):
+		t.Fatal("ticker not delivered")
+	}
+
+	assert.NoError(t, sub.Close())
+	assert.NoError(t, sub.Close())
+	assert.Empty(t, client.Subscriptions())
+	assert.Equal(t, 1, srv.Calls("public/unsubscribe"))
+}
+
+func TestTypedHandler_SharedChannel(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	first := make(chan *models.OrderBookRawNotification, 1)
+	second := make(chan *models.OrderBookRawNotification, 1)
+	sub1, err := client.OnBookRaw("BTC-PERPETUAL", func(n *models.OrderBookRawNotification) { first <- n })
+	assert.NoError(t, err)
+	sub2, err := client.OnBookRaw("BTC-PERPETUAL", func(n *models.OrderBookRawNotification) { second <- n })
+	assert.NoError(t, err)
+
+	// Closing one handler keeps the channel subscribed for the other.
+	assert.NoError(t, sub1.Close())
+	assert.Equal(t, 0, srv.Calls("public/unsubscribe"))
+
+	srv.Notify("book.BTC-PERPETUAL.raw", map[string]interface{}{"instrument_name": "BTC-PERPETUAL", "change_id": 7})
+	select {
+	case n := <-second:
+		assert.Equal(t, int64(7), n.ChangeID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("book not delivered")
+	}
+	assert.Empty(t, first)
+
+	assert.NoError(t, sub2.Close())
+	assert.Equal(t, 1, srv.Calls("public/unsubscribe"))
+}
+
+func TestTypedHandler_CloseKeepsForeignSubscriptions(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	// Subscribed with Subscribe before the typed handler.
+	assert.NoError(t, client.Subscribe([]string{"ticker.BTC-PERPETUAL.100ms"}))
+	sub, err := client.OnTicker("BTC-PERPETUAL", "100ms", func(*models.TickerNotification) {})
+	assert.NoError(t, err)
+	assert.NoError(t, sub.Close())
+	assert.Equal(t, SubscriptionActive, subscriptionState(client, sub.Channel()))
+
+	// Subscribed with Subscribe after the typed handler.
+	sub, err = client.OnTicker("ETH-PERPETUAL", "100ms", func(*models.TickerNotification) {})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Subscribe([]string{sub.Channel()}))
+	assert.NoError(t, sub.Close())
+	assert.Equal(t, SubscriptionActive, subscriptionState(client, sub.Channel()))
+
+	// Still listened to with On.
+	sub, err = client.OnTicker("SOL_USDC-PERPETUAL", "100ms", func(*models.TickerNotification) {})
+	assert.NoError(t, err)
+	client.On(sub.Channel(), func(*models.TickerNotification) {})
+	assert.NoError(t, sub.Close())
+	assert.Equal(t, SubscriptionActive, subscriptionState(client, sub.Channel()))
+
+	assert.Equal(t, 0, srv.Calls("public/unsubscribe"))
+}
+
+func TestTypedHandler_BookSnapshot(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	snapshots := make(chan orderbook.Snapshot, 1)
+	sub, err := client.OnBookSnapshot("BTC-PERPETUAL", "5", 10, "100ms", func(s orderbook.Snapshot) { snapshots <- s })
+	assert.NoError(t, err)
+	assert.Equal(t, "book.BTC-PERPETUAL.5.10.100ms", sub.Channel())
+
+	srv.Notify(sub.Channel(), map[string]interface{}{
+		"instrument_name": "BTC-PERPETUAL",
+		"change_id":       9,
+		"timestamp":       1700000000000,
+		"bids":            [][]float64{{60000, 12}, {59995, 3}},
+		"asks":            [][]float64{{60005, 7}},
+	})
+	select {
+	case s := <-snapshots:
+		assert.Equal(t, "BTC-PERPETUAL", s.Instrument)
+		assert.Equal(t, "5", s.Group)
+		assert.Equal(t, 10, s.Levels)
+		assert.Equal(t, int64(9), s.ChangeID)
+		assert.Equal(t, []orderbook.Level{{Price: 60000, Amount: 12}, {Price: 59995, Amount: 3}}, s.Bids)
+		assert.Equal(t, []orderbook.Level{{Price: 60005, Amount: 7}}, s.Asks)
+	case <-time.After(2 * time.Second):
+		t.Fatal("book not delivered")
+	}
+}
+
+func TestTypedHandler_SubscribeError(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return false })
+	client := newMockClient(t, srv)
+
+	sub, err := client.OnTrades("BTC-PERPETUAL", "raw", func(*models.TradesNotification) {})
+	assert.Nil(t, sub)
+	assert.Error(t, err)
+	assert.Empty(t, client.Subscriptions())
+}
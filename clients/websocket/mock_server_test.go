@@ -0,0 +1,217 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+// mockHandler answers a single JSON-RPC method on the mock server.
+type mockHandler func(params json.RawMessage) (interface{}, *jsonrpc2.Error)
+
+// mockServer is an in-process stand-in for the Deribit WebSocket endpoint.
+type mockServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]mockHandler
+	delays   map[string]time.Duration
+	conns    []*websocket.Conn
+	calls    map[string]int
+	// skew is added to the server clock; noTimestamps drops usIn/usOut.
+	skew         time.Duration
+	noTimestamps bool
+}
+
+type mockRequest struct {
+	ID     *json.RawMessage `json:"id,omitempty"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type mockResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *jsonrpc2.Error  `json:"error,omitempty"`
+	UsIn    int64            `json:"usIn,omitempty"`
+	UsOut   int64            `json:"usOut,omitempty"`
+}
+
+type mockNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+func newMockServer(t *testing.T) *mockServer {
+	t.Helper()
+	s := &mockServer{
+		handlers: make(map[string]mockHandler),
+		delays:   make(map[string]time.Duration),
+		calls:    make(map[string]int),
+	}
+	s.Handle("public/set_heartbeat", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return "ok", nil
+	})
+	s.Handle("public/test", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return map[string]string{"version": "mock"}, nil
+	})
+	s.Handle("public/get_time", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return s.now().UnixMilli(), nil
+	})
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Addr returns the ws:// address of the server.
+func (s *mockServer) Addr() string {
+	return "ws://" + strings.TrimPrefix(s.URL, "http://")
+}
+
+// Handle registers h for method, replacing any previous handler.
+func (s *mockServer) Handle(method string, h mockHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+// Delay holds every response to method for d.
+func (s *mockServer) Delay(method string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays[method] = d
+}
+
+// SetClock shifts the server clock by skew and controls whether responses
+// carry usIn and usOut.
+func (s *mockServer) SetClock(skew time.Duration, timestamps bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.skew = skew
+	s.noTimestamps = !timestamps
+}
+
+func (s *mockServer) now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return time.Now().Add(s.skew)
+}
+
+// Calls returns how often method was received.
+func (s *mockServer) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// Notify pushes a subscription notification to every open connection.
+func (s *mockServer) Notify(channel string, data interface{}) {
+	s.broadcast("subscription", map[string]interface{}{"channel": channel, "data": data})
+}
+
+// Heartbeat sends a heartbeat of the given type to every open connection.
+func (s *mockServer) Heartbeat(typ string) {
+	s.broadcast("heartbeat", map[string]interface{}{"type": typ})
+}
+
+func (s *mockServer) broadcast(method string, params interface{}) {
+	s.mu.Lock()
+	conns := append([]*websocket.Conn(nil), s.conns...)
+	s.mu.Unlock()
+	for _, conn := range conns {
+		_ = wsjson.Write(context.Background(), conn, mockNotification{
+			JSONRPC: "2.0",
+			Method:  method,
+			Params:  params,
+		})
+	}
+}
+
+// DropConnections closes every open connection from the server side.
+func (s *mockServer) DropConnections() {
+	s.mu.Lock()
+	conns := s.conns
+	s.conns = nil
+	s.mu.Unlock()
+	for _, conn := range conns {
+		_ = conn.Close(websocket.StatusGoingAway, "")
+	}
+}
+
+func (s *mockServer) serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.mu.Unlock()
+
+	ctx := r.Context()
+	for {
+		var req mockRequest
+		if err := wsjson.Read(ctx, conn, &req); err != nil {
+			return
+		}
+		go s.reply(ctx, conn, req)
+	}
+}
+
+func (s *mockServer) reply(ctx context.Context, conn *websocket.Conn, req mockRequest) {
+	usIn := s.now()
+	s.mu.Lock()
+	s.calls[req.Method]++
+	h := s.handlers[req.Method]
+	delay := s.delays[req.Method]
+	timestamps := !s.noTimestamps
+	s.mu.Unlock()
+
+	if req.ID == nil {
+		return
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	resp := mockResponse{JSONRPC: "2.0", ID: req.ID}
+	if h == nil {
+		resp.Error = &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "method_not_found"}
+	} else {
+		resp.Result, resp.Error = h(req.Params)
+	}
+	if timestamps {
+		resp.UsIn = usIn.UnixMicro()
+		resp.UsOut = s.now().UnixMicro()
+	}
+	_ = wsjson.Write(ctx, conn, resp)
+}
+
+func newMockClient(t *testing.T, s *mockServer) *DeribitWSClient {
+	t.Helper()
+	return newMockClientWithConfig(t, &deribit.Configuration{
+		WsAddr: s.Addr(),
+	})
+}
+
+func newMockClientWithConfig(t *testing.T, cfg *deribit.Configuration) *DeribitWSClient {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	client, err := Dial(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/coder/websocket"
@@ -12,7 +14,8 @@ import (
 // ObjectStream is a jsonrpc2.ObjectStream that uses a WebSocket to
 // send and receive JSON-RPC 2.0 objects.
 type ObjectStream struct {
-	conn *websocket.Conn
+	conn   *websocket.Conn
+	onRead func(data []byte)
 }
 
 // NewObjectStream creates a new jsonrpc2.ObjectStream for sending and
@@ -21,6 +24,13 @@ func NewObjectStream(conn *websocket.Conn) ObjectStream {
 	return ObjectStream{conn: conn}
 }
 
+// NewObjectStreamWithHook is like NewObjectStream, but calls onRead with the
+// raw bytes of every message before it is decoded. onRead must not retain
+// data.
+func NewObjectStreamWithHook(conn *websocket.Conn, onRead func(data []byte)) ObjectStream {
+	return ObjectStream{conn: conn, onRead: onRead}
+}
+
 // WriteObject implements jsonrpc2.ObjectStream.
 func (t ObjectStream) WriteObject(obj interface{}) error {
 	return wsjson.Write(context.Background(), t.conn, obj)
@@ -28,15 +38,25 @@ func (t ObjectStream) WriteObject(obj interface{}) error {
 
 // ReadObject implements jsonrpc2.ObjectStream.
 func (t ObjectStream) ReadObject(v interface{}) error {
-	err := wsjson.Read(context.Background(), t.conn, v)
-	var e *websocket.CloseError
-	if errors.As(err, &e) {
-		if e.Code == websocket.StatusNormalClosure && e.Error() == io.ErrUnexpectedEOF.Error() {
-			// unwrapping this error.
-			err = io.ErrUnexpectedEOF
+	typ, data, err := t.conn.Read(context.Background())
+	if err != nil {
+		var e *websocket.CloseError
+		if errors.As(err, &e) {
+			if e.Code == websocket.StatusNormalClosure && e.Error() == io.ErrUnexpectedEOF.Error() {
+				// unwrapping this error.
+				err = io.ErrUnexpectedEOF
+			}
 		}
+		return err
+	}
+	if typ != websocket.MessageText {
+		_ = t.conn.Close(websocket.StatusUnsupportedData, "expected text message")
+		return fmt.Errorf("expected text message but got %v", typ)
+	}
+	if t.onRead != nil {
+		t.onRead(data)
 	}
-	return err
+	return json.Unmarshal(data, v)
 }
 
 // Close implements jsonrpc2.ObjectStream.
@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+
+	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// OrderPlacer is the part of a client needed to place orders safely. Both
+// DeribitWSClient and the REST client implement it.
+type OrderPlacer interface {
+	BuyContext(context.Context, *models.BuyParams) (models.BuyResponse, error)
+	SellContext(context.Context, *models.SellParams) (models.SellResponse, error)
+	GetOpenOrdersByInstrumentContext(context.Context, *models.GetOpenOrdersByInstrumentParams) ([]models2.Order, error)
+	GetOrderHistoryByInstrumentContext(context.Context, *models.GetOrderHistoryByInstrumentParams) ([]models2.Order, error)
+}
+
+var _ OrderPlacer = (*DeribitWSClient)(nil)
+
+// FindOrderByLabel looks for the newest order on instrument with label,
+// first among the open orders and then in the recent order history.
+func FindOrderByLabel(ctx context.Context, api OrderPlacer, instrument string, label string) (order models2.Order, found bool, err error) {
+	open, err := api.GetOpenOrdersByInstrumentContext(ctx, &models.GetOpenOrdersByInstrumentParams{InstrumentName: instrument})
+	if err != nil {
+		return
+	}
+	if order, found = newestByLabel(open, label); found {
+		return
+	}
+	history, err := api.GetOrderHistoryByInstrumentContext(ctx, &models.GetOrderHistoryByInstrumentParams{
+		InstrumentName:  instrument,
+		Count:           100,
+		IncludeUnfilled: true,
+	})
+	if err != nil {
+		return
+	}
+	order, found = newestByLabel(history, label)
+	return
+}
+
+func newestByLabel(orders []models2.Order, label string) (newest models2.Order, found bool) {
+	for _, o := range orders {
+		if o.Label == label && (!found || o.CreationTimestamp > newest.CreationTimestamp) {
+			newest, found = o, true
+		}
+	}
+	return
+}
+
+// BuyWithRetry places a buy order and, if the call fails transiently,
+// checks by params.Label whether the order landed before sending it again.
+// Labels must be unique per order for the check to be meaningful; without
+// a label the order is sent once. When the check finds the order, the
+// response carries it without trades.
+func BuyWithRetry(ctx context.Context, api OrderPlacer, policy *deribit.RetryPolicy, params *models.BuyParams) (models.BuyResponse, error) {
+	var result models.BuyResponse
+	err := placeWithRetry(ctx, api, policy, params.InstrumentName, params.Label, func() (err error) {
+		result, err = api.BuyContext(ctx, params)
+		return
+	}, func(order models2.Order) {
+		result = models.BuyResponse{Order: order}
+	})
+	return result, err
+}
+
+// SellWithRetry is BuyWithRetry for sell orders.
+func SellWithRetry(ctx context.Context, api OrderPlacer, policy *deribit.RetryPolicy, params *models.SellParams) (models.SellResponse, error) {
+	var result models.SellResponse
+	err := placeWithRetry(ctx, api, policy, params.InstrumentName, params.Label, func() (err error) {
+		result, err = api.SellContext(ctx, params)
+		return
+	}, func(order models2.Order) {
+		result = models.SellResponse{Order: order}
+	})
+	return result, err
+}
+
+// placeWithRetry sends an order with place. After a transient failure it
+// waits out the backoff and looks the order up by label; found is called if
+// it landed, otherwise the order is sent again. If the lookup itself fails
+// the outcome is unknown and the original error is returned.
+func placeWithRetry(ctx context.Context, api OrderPlacer, policy *deribit.RetryPolicy, instrument string, label string,
+	place func() error, found func(models2.Order)) error {
+	attempts := 1
+	if policy != nil && label != "" {
+		attempts = policy.MaxAttempts
+	}
+	for attempt := 0; ; attempt++ {
+		err := place()
+		if !deribit.IsRetryable(err) || attempt+1 >= attempts {
+			return err
+		}
+		if wErr := policy.Wait(ctx, attempt); wErr != nil {
+			return err
+		}
+		order, ok, lErr := FindOrderByLabel(ctx, api, instrument, label)
+		if lErr != nil {
+			return errors.Join(err, lErr)
+		}
+		if ok {
+			found(order)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func testRetryPolicy() *deribit.RetryPolicy {
+	return &deribit.RetryPolicy{Backoff: deribit.Backoff{InitialInterval: time.Millisecond}, MaxAttempts: 3}
+}
+
+func TestClient_RetryIdempotent(t *testing.T) {
+	srv := newMockServer(t)
+	var calls atomic.Int32
+	srv.Handle("public/get_instruments", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		if calls.Add(1) < 3 {
+			return nil, &jsonrpc2.Error{Code: deribit.CodeTooManyRequests, Message: "too_many_requests"}
+		}
+		return []models.Instrument{{InstrumentName: "BTC-PERPETUAL"}}, nil
+	})
+	srv.Handle("private/buy", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return nil, &jsonrpc2.Error{Code: deribit.CodeTooManyRequests, Message: "too_many_requests"}
+	})
+	client := newMockClientWithConfig(t, &deribit.Configuration{WsAddr: srv.Addr(), Retry: testRetryPolicy()})
+
+	instruments, err := client.GetInstruments(&models.GetInstrumentsParams{Currency: "BTC"})
+	assert.NoError(t, err)
+	assert.Len(t, instruments, 1)
+	assert.Equal(t, 3, srv.Calls("public/get_instruments"))
+
+	_, err = client.Buy(&models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: "market"})
+	assert.True(t, errors.Is(err, deribit.ErrTooManyRequests))
+	assert.Equal(t, 1, srv.Calls("private/buy"))
+}
+
+func TestClient_RetryNotAuthenticated(t *testing.T) {
+	srv := newMockServer(t)
+	client := newMockClientWithConfig(t, &deribit.Configuration{WsAddr: srv.Addr(), Retry: testRetryPolicy()})
+
+	params := &struct {
+		models2.Token
+		InstrumentName string `json:"instrument_name"`
+	}{InstrumentName: "BTC-PERPETUAL"}
+	var position models.Position
+	err := client.CallContext(context.Background(), "private/get_position", params, &position)
+	assert.Equal(t, ErrAuthenticationIsRequired, err)
+}
+
+// fakePlacer fails the first order with a transient error and reports the
+// given orders on lookup.
+type fakePlacer struct {
+	buys    int
+	lookups int
+	landed  []models2.Order
+}
+
+func (f *fakePlacer) BuyContext(_ context.Context, params *models.BuyParams) (models.BuyResponse, error) {
+	f.buys++
+	if f.buys == 1 {
+		return models.BuyResponse{}, errors.New("connection reset by peer")
+	}
+	return models.BuyResponse{Order: models2.Order{OrderID: "resent", Label: params.Label}}, nil
+}
+
+func (f *fakePlacer) SellContext(context.Context, *models.SellParams) (models.SellResponse, error) {
+	return models.SellResponse{}, errors.New("not implemented")
+}
+
+func (f *fakePlacer) GetOpenOrdersByInstrumentContext(context.Context, *models.GetOpenOrdersByInstrumentParams) ([]models2.Order, error) {
+	f.lookups++
+	return nil, nil
+}
+
+func (f *fakePlacer) GetOrderHistoryByInstrumentContext(context.Context, *models.GetOrderHistoryByInstrumentParams) ([]models2.Order, error) {
+	return f.landed, nil
+}
+
+func TestBuyWithRetry(t *testing.T) {
+	params := &models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10, Type: "market", Label: "strategy-42"}
+
+	// The order landed although the response was lost: it is not resent.
+	landed := &fakePlacer{landed: []models2.Order{
+		{OrderID: "old", Label: "strategy-42", CreationTimestamp: 1},
+		{OrderID: "other", Label: "strategy-43", CreationTimestamp: 3},
+		{OrderID: "landed", Label: "strategy-42", CreationTimestamp: 2},
+	}}
+	result, err := BuyWithRetry(context.Background(), landed, testRetryPolicy(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "landed", result.Order.OrderID)
+	assert.Equal(t, 1, landed.buys)
+
+	// The order did not land: it is sent again.
+	lost := &fakePlacer{}
+	result, err = BuyWithRetry(context.Background(), lost, testRetryPolicy(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "resent", result.Order.OrderID)
+	assert.Equal(t, 2, lost.buys)
+	assert.Equal(t, 1, lost.lookups)
+
+	// Without a label the outcome cannot be checked, so nothing is retried.
+	unlabelled := &fakePlacer{}
+	_, err = BuyWithRetry(context.Background(), unlabelled, testRetryPolicy(), &models.BuyParams{InstrumentName: "BTC-PERPETUAL", Amount: 10})
+	assert.Error(t, err)
+	assert.Equal(t, 1, unlabelled.buys)
+	assert.Zero(t, unlabelled.lookups)
+}
@@ -0,0 +1,360 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// ErrSubscriptionRejected is recorded for a channel the server left out of
+// its subscribe response.
+var ErrSubscriptionRejected = errors.New("subscription rejected by server")
+
+// SubscriptionState is the state of one subscribed channel.
+type SubscriptionState string
+
+const (
+	// SubscriptionPending channels have not been confirmed by the server
+	// on the current connection yet.
+	SubscriptionPending SubscriptionState = "pending"
+	// SubscriptionActive channels were confirmed in a subscribe response.
+	SubscriptionActive SubscriptionState = "active"
+	// SubscriptionFailed channels were rejected or the request failed; they
+	// are retried in the background.
+	SubscriptionFailed SubscriptionState = "failed"
+)
+
+// SubscriptionStatus describes one channel the client wants to receive.
+type SubscriptionStatus struct {
+	Channel string
+	State   SubscriptionState
+	// Err is the last subscribe error, set for SubscriptionFailed.
+	Err error
+}
+
+// subscriptionManager tracks the desired channels and their server state.
+type subscriptionManager struct {
+	mu       sync.Mutex
+	channels map[string]*SubscriptionStatus
+	// failed is signalled whenever a channel enters SubscriptionFailed.
+	failed chan struct{}
+}
+
+func newSubscriptionManager() *subscriptionManager {
+	return &subscriptionManager{
+		channels: make(map[string]*SubscriptionStatus),
+		failed:   make(chan struct{}, 1),
+	}
+}
+
+// add registers channels as pending unless they are already tracked.
+func (m *subscriptionManager) add(channels []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ch := range channels {
+		if _, ok := m.channels[ch]; !ok {
+			m.channels[ch] = &SubscriptionStatus{Channel: ch, State: SubscriptionPending}
+		}
+	}
+}
+
+// remove stops tracking channels and returns those that were active.
+func (m *subscriptionManager) remove(channels []string) (active []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, ch := range channels {
+		if s, ok := m.channels[ch]; ok {
+			if s.State == SubscriptionActive {
+				active = append(active, ch)
+			}
+			delete(m.channels, ch)
+		}
+	}
+	return
+}
+
+// clear stops tracking every channel and returns the channels it tracked.
+func (m *subscriptionManager) clear() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	channels := make([]string, 0, len(m.channels))
+	for ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	m.channels = make(map[string]*SubscriptionStatus)
+	return channels
+}
+
+// reset marks every channel pending, as after a new connection.
+func (m *subscriptionManager) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.channels {
+		s.State = SubscriptionPending
+		s.Err = nil
+	}
+}
+
+// unconfirmed returns the channels that are pending or failed.
+func (m *subscriptionManager) unconfirmed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var channels []string
+	for ch, s := range m.channels {
+		if s.State != SubscriptionActive {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// hasFailed reports whether any channel is in SubscriptionFailed.
+func (m *subscriptionManager) hasFailed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.channels {
+		if s.State == SubscriptionFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm applies a subscribe outcome: channels listed by the server become
+// active, the other requested channels fail with err, or with
+// ErrSubscriptionRejected if the request itself succeeded.
+func (m *subscriptionManager) confirm(requested []string, confirmed []string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := make(map[string]bool, len(confirmed))
+	for _, ch := range confirmed {
+		ok[ch] = true
+	}
+	if err == nil {
+		err = ErrSubscriptionRejected
+	}
+	anyFailed := false
+	for _, ch := range requested {
+		s, tracked := m.channels[ch]
+		if !tracked {
+			// Unsubscribed while the request was in flight.
+			continue
+		}
+		if ok[ch] {
+			s.State = SubscriptionActive
+			s.Err = nil
+		} else {
+			s.State = SubscriptionFailed
+			s.Err = err
+			anyFailed = true
+		}
+	}
+	if anyFailed {
+		select {
+		case m.failed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// status returns the status of channel, if tracked.
+func (m *subscriptionManager) status(channel string) (SubscriptionStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.channels[channel]
+	if !ok {
+		return SubscriptionStatus{}, false
+	}
+	return *s, true
+}
+
+// list returns a copy of every tracked channel's status, sorted by channel.
+func (m *subscriptionManager) list() []SubscriptionStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]SubscriptionStatus, 0, len(m.channels))
+	for _, s := range m.channels {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Channel < list[j].Channel })
+	return list
+}
+
+// isPrivateChannel reports whether channel needs private/subscribe. Names
+// unknown to the channels package are private if they start with "user.".
+func isPrivateChannel(channel string) bool {
+	if ch, err := channels.Parse(channel); err == nil {
+		return ch.Private()
+	}
+	return strings.HasPrefix(channel, "user.")
+}
+
+func splitChannels(channels []string) (public []string, private []string) {
+	for _, ch := range channels {
+		if isPrivateChannel(ch) {
+			private = append(private, ch)
+		} else {
+			public = append(public, ch)
+		}
+	}
+	return
+}
+
+// Subscribe adds channels to the subscription set and subscribes to them if
+// the client is connected. Channels that fail are retried in the background
+// and after every reconnect; the returned error reports this attempt only.
+func (c *DeribitWSClient) Subscribe(channels []string) error {
+	return c.SubscribeContext(c.ctx, channels)
+}
+
+func (c *DeribitWSClient) SubscribeContext(ctx context.Context, channels []string) error {
+	c.handlers.disown(channels)
+	return c.subscribe(ctx, channels)
+}
+
+// subscribe implements SubscribeContext without taking over channels owned
+// by typed handlers.
+func (c *DeribitWSClient) subscribe(ctx context.Context, channels []string) error {
+	c.subs.add(channels)
+	c.dispatcher.add(channels)
+	if !c.IsConnected() {
+		return nil
+	}
+	return c.subscribePending(ctx)
+}
+
+// Unsubscribe removes channels from the subscription set and unsubscribes
+// from those that are active. Notifications still queued for the channels
+// are discarded.
+func (c *DeribitWSClient) Unsubscribe(channels []string) error {
+	return c.UnsubscribeContext(c.ctx, channels)
+}
+
+func (c *DeribitWSClient) UnsubscribeContext(ctx context.Context, channels []string) error {
+	c.subscribeMu.Lock()
+	defer c.subscribeMu.Unlock()
+
+	active := c.subs.remove(channels)
+	defer c.dispatcher.remove(channels)
+	if len(active) == 0 || !c.IsConnected() {
+		return nil
+	}
+	public, private := splitChannels(active)
+	var errs []error
+	if len(public) > 0 {
+		if _, err := c.PublicUnsubscribeContext(ctx, &models.UnsubscribeParams{Channels: public}); err != nil {
+			errs = append(errs, fmt.Errorf("public/unsubscribe: %w", err))
+		}
+	}
+	if len(private) > 0 {
+		if _, err := c.PrivateUnsubscribeContext(ctx, &models.UnsubscribeParams{Channels: private}); err != nil {
+			errs = append(errs, fmt.Errorf("private/unsubscribe: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UnsubscribeAll clears the subscription set, drops every public and, when
+// authenticated, private subscription on the server and discards queued
+// notifications.
+func (c *DeribitWSClient) UnsubscribeAll() error {
+	return c.UnsubscribeAllContext(c.ctx)
+}
+
+func (c *DeribitWSClient) UnsubscribeAllContext(ctx context.Context) error {
+	c.subscribeMu.Lock()
+	defer c.subscribeMu.Unlock()
+
+	channels := c.subs.clear()
+	defer c.dispatcher.removeAll(channels)
+	if !c.IsConnected() {
+		return nil
+	}
+	var errs []error
+	if _, err := c.PublicUnsubscribeAllContext(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("public/unsubscribe_all: %w", err))
+	}
+	if c.token() != "" {
+		if _, err := c.PrivateUnsubscribeAllContext(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("private/unsubscribe_all: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscriptions returns the state of every channel in the subscription set.
+func (c *DeribitWSClient) Subscriptions() []SubscriptionStatus {
+	return c.subs.list()
+}
+
+// subscribePending sends subscribe requests for every pending or failed
+// channel and records the server's answer.
+func (c *DeribitWSClient) subscribePending(ctx context.Context) error {
+	c.subscribeMu.Lock()
+	defer c.subscribeMu.Unlock()
+
+	public, private := splitChannels(c.subs.unconfirmed())
+	var errs []error
+	if len(public) > 0 {
+		result, err := c.PublicSubscribeContext(ctx, &models.SubscribeParams{Channels: public})
+		c.subs.confirm(public, result, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("public/subscribe: %w", err))
+		}
+	}
+	if len(private) > 0 {
+		result, err := c.PrivateSubscribeContext(ctx, &models.SubscribeParams{Channels: private})
+		c.subs.confirm(private, result, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("private/subscribe: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resubscribeLoop retries failed channels with the reconnect backoff until
+// they are confirmed or the connection goes away.
+func (c *DeribitWSClient) resubscribeLoop(heartCancel chan struct{}) {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case <-c.subs.failed:
+		case <-heartCancel:
+			return
+		case <-c.ctx.Done():
+			return
+		}
+
+		for attempt := 0; c.subs.hasFailed(); attempt++ {
+			timer := time.NewTimer(c.reconnectPolicy.Duration(attempt))
+			select {
+			case <-timer.C:
+			case <-heartCancel:
+				timer.Stop()
+				return
+			case <-c.ctx.Done():
+				timer.Stop()
+				return
+			}
+			_ = c.subscribePending(c.ctx)
+		}
+	}
+}
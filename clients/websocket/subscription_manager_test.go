@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/jsonrpc2"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// handleSubscribe answers public/subscribe with every requested channel for
+// which accept returns true.
+func handleSubscribe(srv *mockServer, method string, accept func(channel string) bool) {
+	srv.Handle(method, func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p models.SubscribeParams
+		_ = json.Unmarshal(raw, &p)
+		result := []string{}
+		for _, ch := range p.Channels {
+			if accept(ch) {
+				result = append(result, ch)
+			}
+		}
+		return result, nil
+	})
+}
+
+func fastRetryConfig(srv *mockServer) *deribit.Configuration {
+	return &deribit.Configuration{
+		WsAddr:        srv.Addr(),
+		AutoReconnect: true,
+		Reconnect: &deribit.ReconnectPolicy{
+			Backoff: deribit.Backoff{InitialInterval: 10 * time.Millisecond, Multiplier: 2},
+		},
+	}
+}
+
+func subscriptionState(client *DeribitWSClient, channel string) SubscriptionState {
+	for _, s := range client.Subscriptions() {
+		if s.Channel == channel {
+			return s.State
+		}
+	}
+	return ""
+}
+
+func TestSubscribe_FailedChannelsAreRetried(t *testing.T) {
+	srv := newMockServer(t)
+	var acceptAll int32
+	handleSubscribe(srv, "public/subscribe", func(ch string) bool {
+		return ch == "trades.BTC-PERPETUAL.raw" || atomic.LoadInt32(&acceptAll) == 1
+	})
+	client := newMockClientWithConfig(t, fastRetryConfig(srv))
+
+	err := client.Subscribe([]string{"trades.BTC-PERPETUAL.raw", "ticker.BTC-PERPETUAL.raw"})
+	assert.NoError(t, err)
+
+	statuses := client.Subscriptions()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, SubscriptionStatus{Channel: "ticker.BTC-PERPETUAL.raw", State: SubscriptionFailed, Err: ErrSubscriptionRejected}, statuses[0])
+	assert.Equal(t, SubscriptionStatus{Channel: "trades.BTC-PERPETUAL.raw", State: SubscriptionActive}, statuses[1])
+
+	atomic.StoreInt32(&acceptAll, 1)
+	assert.Eventually(t, func() bool {
+		return subscriptionState(client, "ticker.BTC-PERPETUAL.raw") == SubscriptionActive
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestSubscribe_Error(t *testing.T) {
+	srv := newMockServer(t)
+	srv.Handle("public/subscribe", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return nil, &jsonrpc2.Error{Code: deribit.CodeTooManyRequests, Message: "too_many_requests"}
+	})
+	client := newMockClient(t, srv)
+
+	err := client.Subscribe([]string{"ticker.BTC-PERPETUAL.raw"})
+	assert.True(t, errors.Is(err, deribit.ErrTooManyRequests))
+
+	statuses := client.Subscriptions()
+	assert.Equal(t, SubscriptionFailed, statuses[0].State)
+	assert.True(t, errors.Is(statuses[0].Err, deribit.ErrTooManyRequests))
+}
+
+func TestUnsubscribe(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	client := newMockClientWithConfig(t, fastRetryConfig(srv))
+
+	assert.NoError(t, client.Subscribe([]string{"trades.BTC-PERPETUAL.raw", "ticker.BTC-PERPETUAL.raw"}))
+	assert.NoError(t, client.Unsubscribe([]string{"ticker.BTC-PERPETUAL.raw", "book.ETH-PERPETUAL.raw"}))
+	assert.Equal(t, 1, srv.Calls("public/unsubscribe"))
+	assert.Equal(t, []SubscriptionStatus{{Channel: "trades.BTC-PERPETUAL.raw", State: SubscriptionActive}}, client.Subscriptions())
+
+	// The unsubscribed channel does not come back after a reconnect.
+	var resubscribed []string
+	var mu sync.Mutex
+	srv.Handle("public/subscribe", func(raw json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		var p models.SubscribeParams
+		_ = json.Unmarshal(raw, &p)
+		mu.Lock()
+		resubscribed = append(resubscribed, p.Channels...)
+		mu.Unlock()
+		return p.Channels, nil
+	})
+	states := recordStates(client, StateResubscribed)
+	srv.DropConnections()
+	waitState(t, states, StateResubscribed)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"trades.BTC-PERPETUAL.raw"}, resubscribed)
+}
+
+func TestUnsubscribeAll(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	srv.Handle("public/unsubscribe_all", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return "ok", nil
+	})
+	client := newMockClient(t, srv)
+
+	assert.NoError(t, client.Subscribe([]string{"trades.BTC-PERPETUAL.raw"}))
+	assert.NoError(t, client.UnsubscribeAll())
+	assert.Empty(t, client.Subscriptions())
+	assert.Equal(t, 1, srv.Calls("public/unsubscribe_all"))
+	// Not authenticated, so there is nothing private to drop.
+	assert.Equal(t, 0, srv.Calls("private/unsubscribe_all"))
+}
+
+func TestSubscribe_Concurrent(t *testing.T) {
+	srv := newMockServer(t)
+	handleSubscribe(srv, "public/subscribe", func(string) bool { return true })
+	handleSubscribe(srv, "public/unsubscribe", func(string) bool { return true })
+	client := newMockClient(t, srv)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			channel := fmt.Sprintf("ticker.BTC-%d.raw", i)
+			assert.NoError(t, client.Subscribe([]string{channel}))
+			if i%2 == 0 {
+				assert.NoError(t, client.Unsubscribe([]string{channel}))
+			}
+			_ = client.Subscriptions()
+		}(i)
+	}
+	wg.Wait()
+
+	statuses := client.Subscriptions()
+	assert.Len(t, statuses, 10)
+	for _, s := range statuses {
+		assert.Equal(t, SubscriptionActive, s.State)
+	}
+}
@@ -1,167 +1,112 @@
 package websocket
 
 import (
+	"log"
+
 	websockecmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/deribit"
 	"github.com/xingxing/deribit-api/pkg/models"
-	"log"
-	"strings"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
 func (c *DeribitWSClient) subscriptionsProcess(event *websockecmodels.Event) {
 	if c.debugMode {
-		log.Printf("Channel: %v %v", event.Channel, string(event.Data))
+		log.Printf("Channel: %v %v", event.Channel, deribit.Redact(string(event.Data)))
 	}
-	if event.Channel == "announcements" {
-		var notification models.AnnouncementsNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "book") {
-		count := strings.Count(event.Channel, ".")
-		if count == 2 {
-			// book.BTC-PERPETUAL.raw
-			// book.BTC-PERPETUAL.100ms
-			if strings.HasSuffix(event.Channel, ".raw") {
-				var notification models.OrderBookRawNotification
-				err := jsoniter.Unmarshal(event.Data, &notification)
-				if err != nil {
-					log.Printf("%v", err)
-					return
-				}
-				c.Emit(event.Channel, &notification)
-			} else {
-				var notification models.OrderBookNotification
-				err := jsoniter.Unmarshal(event.Data, &notification)
-				if err != nil {
-					log.Printf("%v", err)
-					return
-				}
-				c.Emit(event.Channel, &notification)
-			}
-		} else if count == 4 {
-			// book.BTC-PERPETUAL.none.10.100ms
-			var notification models.OrderBookGroupNotification
-			err := jsoniter.Unmarshal(event.Data, &notification)
-			if err != nil {
-				log.Printf("%v", err)
-				return
-			}
-			c.Emit(event.Channel, &notification)
-		}
-	} else if strings.HasPrefix(event.Channel, "deribit_price_index") {
-		var notification models.DeribitPriceIndexNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "deribit_price_ranking") {
-		var notification models.DeribitPriceRankingNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "estimated_expiration_price") {
-		var notification models.EstimatedExpirationPriceNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "markprice.options") {
-		var notification models.MarkpriceOptionsNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "perpetual") {
-		var notification models.PerpetualNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "quote") {
-		var notification models.QuoteNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "ticker") {
-		var notification models.TickerNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "trades") {
-		var notification models.TradesNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "user.changes") {
-		var notification models.UserChangesNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
+	ch, err := channels.Parse(event.Channel)
+	if err != nil {
+		c.unhandled(event)
+		return
+	}
+	switch ch.Type {
+	case channels.TypeAnnouncements:
+		process[models.AnnouncementsNotification](c, event)
+	case channels.TypeBlockTradeConfirmations:
+		process[models.BlockTradeConfirmationNotification](c, event)
+	case channels.TypeBook:
+		if ch.Interval == channels.IntervalRaw {
+			process[models.OrderBookRawNotification](c, event)
+		} else {
+			process[models.OrderBookNotification](c, event)
 		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "user.orders") {
-		if string(event.Data)[0] == '{' {
-			var notification models.UserOrderNotification
+	case channels.TypeBookGroup, channels.TypeBookDepth:
+		process[models.OrderBookGroupNotification](c, event)
+	case channels.TypeChartTrades:
+		process[models.ChartTradesNotification](c, event)
+	case channels.TypeDeribitPriceIndex:
+		process[models.DeribitPriceIndexNotification](c, event)
+	case channels.TypeDeribitPriceRanking:
+		process[models.DeribitPriceRankingNotification](c, event)
+	case channels.TypeDeribitPriceStatistics:
+		process[models.DeribitPriceStatisticsNotification](c, event)
+	case channels.TypeDeribitVolatilityIndex:
+		process[models.DeribitVolatilityIndexNotification](c, event)
+	case channels.TypeEstimatedExpirationPrice:
+		process[models.EstimatedExpirationPriceNotification](c, event)
+	case channels.TypeIncrementalTicker:
+		process[models.IncrementalTickerNotification](c, event)
+	case channels.TypeInstrumentState:
+		process[models.InstrumentStateNotification](c, event)
+	case channels.TypeMarkpriceOptions:
+		process[models.MarkpriceOptionsNotification](c, event)
+	case channels.TypePerpetual:
+		process[models.PerpetualNotification](c, event)
+	case channels.TypePlatformState:
+		process[models.PlatformStateNotification](c, event)
+	case channels.TypeQuote:
+		process[models.QuoteNotification](c, event)
+	case channels.TypeTicker:
+		process[models.TickerNotification](c, event)
+	case channels.TypeTrades, channels.TypeTradesByKind:
+		process[models.TradesNotification](c, event)
+	case channels.TypeUserAccessLog:
+		process[models.UserAccessLogNotification](c, event)
+	case channels.TypeUserChanges, channels.TypeUserChangesByInstrument:
+		process[models.UserChangesNotification](c, event)
+	case channels.TypeUserLock:
+		process[models.UserLockNotification](c, event)
+	case channels.TypeUserMMPTrigger:
+		process[models.UserMMPTriggerNotification](c, event)
+	case channels.TypeUserOrders, channels.TypeUserOrdersByInstrument:
+		if len(event.Data) > 0 && event.Data[0] == '{' {
+			// Raw channels send a single order rather than a list.
 			var order websockecmodels.Order
-			err := jsoniter.Unmarshal(event.Data, &order)
-			if err != nil {
+			if err := jsoniter.Unmarshal(event.Data, &order); err != nil {
 				log.Printf("%v", err)
 				return
 			}
-			notification = append(notification, order)
-			c.Emit(event.Channel, &notification)
+			notification := models.UserOrderNotification{order}
+			c.emit(event.Channel, &notification)
 		} else {
-			var notification models.UserOrderNotification
-			err := jsoniter.Unmarshal(event.Data, &notification)
-			if err != nil {
-				log.Printf("%v", err)
-				return
-			}
-			c.Emit(event.Channel, &notification)
-		}
-	} else if strings.HasPrefix(event.Channel, "user.portfolio") {
-		var notification models.PortfolioNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
+			process[models.UserOrderNotification](c, event)
 		}
-		c.Emit(event.Channel, &notification)
-	} else if strings.HasPrefix(event.Channel, "user.trades") {
-		var notification models.UserTradesNotification
-		err := jsoniter.Unmarshal(event.Data, &notification)
-		if err != nil {
-			log.Printf("%v", err)
-			return
-		}
-		c.Emit(event.Channel, &notification)
-	} else {
-		log.Printf("%v", string(event.Data))
+	case channels.TypeUserPortfolio:
+		process[models.PortfolioNotification](c, event)
+	case channels.TypeUserTrades, channels.TypeUserTradesByInstrument:
+		process[models.UserTradesNotification](c, event)
+	default:
+		c.unhandled(event)
+	}
+}
+
+// unhandled emits an UnhandledEvent for a channel without a notification
+// model. It is only logged if nobody listens.
+func (c *DeribitWSClient) unhandled(event *websockecmodels.Event) {
+	if c.emitter.GetListenerCount(EventUnhandled) == 0 {
+		log.Printf("unhandled channel %v: %v", event.Channel, deribit.Redact(string(event.Data)))
+		return
+	}
+	c.Emit(EventUnhandled, &UnhandledEvent{Channel: event.Channel, Data: event.Data})
+}
+
+// process decodes the notification data as T and emits it.
+func process[T any](c *DeribitWSClient, event *websockecmodels.Event) {
+	var notification T
+	if err := jsoniter.Unmarshal(event.Data, &notification); err != nil {
+		log.Printf("%v", err)
+		return
 	}
+	c.emit(event.Channel, &notification)
 }
@@ -0,0 +1,56 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chuckpreslar/emission"
+	"github.com/stretchr/testify/assert"
+	websockecmodels "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestSubscriptionsProcess_Routing(t *testing.T) {
+	tests := []struct {
+		channel string
+		data    string
+		want    interface{}
+	}{
+		{"book.BTC-PERPETUAL.raw", `{"change_id":2,"prev_change_id":1}`, &models.OrderBookRawNotification{ChangeID: 2, PrevChangeID: 1}},
+		{"book.BTC-PERPETUAL.100ms", `{"type":"snapshot","change_id":3}`, &models.OrderBookNotification{Type: "snapshot", ChangeID: 3}},
+		{"book.BTC-PERPETUAL.none.10.100ms", `{"change_id":4}`, &models.OrderBookGroupNotification{ChangeID: 4}},
+		{"trades.future.BTC.100ms", `[]`, &models.TradesNotification{}},
+		{"user.orders.BTC-PERPETUAL.raw", `{"order_id":"a"}`, &models.UserOrderNotification{{OrderID: "a"}}},
+		{"user.orders.future.BTC.100ms", `[{"order_id":"b"}]`, &models.UserOrderNotification{{OrderID: "b"}}},
+		{"book.BTC-PERPETUAL.10.100ms", `{"change_id":5,"bids":[[100.5,10]]}`, &models.OrderBookGroupNotification{ChangeID: 5, Bids: [][]float64{{100.5, 10}}}},
+		{"chart.trades.BTC-PERPETUAL.1", `{"tick":1,"open":2,"close":3}`, &models.ChartTradesNotification{Tick: 1, Open: 2, Close: 3}},
+		{"incremental_ticker.BTC-PERPETUAL", `{"type":"change","last_price":5}`, &models.IncrementalTickerNotification{Type: "change", TickerNotification: models.TickerNotification{LastPrice: 5}}},
+		{"instrument.state.any.BTC", `{"state":"created","instrument_name":"BTC-1JAN30"}`, &models.InstrumentStateNotification{State: "created", InstrumentName: "BTC-1JAN30"}},
+		{"platform_state", `{"price_index":"btc_usd","locked":true}`, &models.PlatformStateNotification{PriceIndex: "btc_usd", Locked: true}},
+		{"deribit_volatility_index.btc_usd", `{"volatility":60.5}`, &models.DeribitVolatilityIndexNotification{Volatility: 60.5}},
+		{"user.mmp_trigger.btc_usd", `{"index_name":"btc_usd","frozen_until":9}`, &models.UserMMPTriggerNotification{IndexName: "btc_usd", FrozenUntil: 9}},
+		{"user.access_log", `{"log":"success","ip":"1.2.3.4"}`, &models.UserAccessLogNotification{Log: "success", IP: "1.2.3.4"}},
+		{"user.lock", `{"locked":true,"currency":"ALL"}`, &models.UserLockNotification{Locked: true, Currency: "ALL"}},
+		{"block_trade_confirmations", `{"nonce":"n","state":{"value":"initial"}}`, &models.BlockTradeConfirmationNotification{Nonce: "n", State: models.BlockTradeState{Value: "initial"}}},
+	}
+
+	for _, tt := range tests {
+		c := &DeribitWSClient{emitter: emission.NewEmitter(), handlers: newHandlerRegistry()}
+		var got interface{}
+		c.handlers.add(tt.channel, func(v interface{}) { got = v })
+		c.subscriptionsProcess(&websockecmodels.Event{Channel: tt.channel, Data: []byte(tt.data)})
+		assert.Equal(t, tt.want, got, tt.channel)
+	}
+}
+
+func TestSubscriptionsProcess_Unhandled(t *testing.T) {
+	c := &DeribitWSClient{emitter: emission.NewEmitter(), handlers: newHandlerRegistry()}
+	called := false
+	c.handlers.add("ticker.BTC-PERPETUAL.1s", func(interface{}) { called = true })
+	var unhandled *UnhandledEvent
+	c.On(EventUnhandled, func(e *UnhandledEvent) { unhandled = e })
+
+	c.subscriptionsProcess(&websockecmodels.Event{Channel: "ticker.BTC-PERPETUAL.1s", Data: []byte(`{"a":1}`)})
+	assert.False(t, called)
+	assert.Equal(t, &UnhandledEvent{Channel: "ticker.BTC-PERPETUAL.1s", Data: json.RawMessage(`{"a":1}`)}, unhandled)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/xingxing/deribit-api/clients/websocket"
 	"github.com/xingxing/deribit-api/pkg/deribit"
 	"github.com/xingxing/deribit-api/pkg/models"
@@ -9,7 +10,11 @@ import (
 
 func main() {
 	cfg := deribit.GetConfig()
-	client := websocket.NewDeribitWsClient(cfg)
+	client, err := websocket.Dial(context.Background(), cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
 
 	_, gErr := client.GetTime()
 	if gErr != nil {
@@ -20,8 +25,6 @@ func main() {
 		return
 	}
 
-	var err error
-
 	// GetBookSummaryByCurrency
 	//getBookSummaryByCurrencyParams := &models.GetBookSummaryByCurrencyParams{
 	//	Currency: "BTC",
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/xingxing/deribit-api/clients/websocket"
 	"github.com/xingxing/deribit-api/pkg/deribit"
 	"github.com/xingxing/deribit-api/pkg/models"
+	"log"
 )
 
 func main() {
 	cfg := deribit.GetConfig()
-	client := websocket.NewDeribitWsClient(cfg)
+	client, err := websocket.Dial(context.Background(), cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
 
 	_, gErr := client.GetTime()
 	if gErr != nil {
@@ -0,0 +1,110 @@
+// Command recorder records the order books and trades of instruments to
+// compact binary files, one per instrument per UTC day:
+//
+//	recorder -dir data -instruments BTC-PERPETUAL,ETH-PERPETUAL
+//
+// The connection is configured from the DERIBIT_* environment variables;
+// book.{instrument}.raw needs DERIBIT_API_KEY and DERIBIT_API_SECRET. With
+// -dump it prints a recording as JSON lines instead:
+//
+//	recorder -dump data/BTC-PERPETUAL/2024-01-02.drec
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/xingxing/deribit-api/clients/websocket"
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/recorder"
+)
+
+func main() {
+	dir := flag.String("dir", "recordings", "directory of the recordings")
+	instruments := flag.String("instruments", "BTC-PERPETUAL", "comma separated instruments to record")
+	tradesInterval := flag.String("trades-interval", string(channels.Interval100ms), "interval of the trades channels")
+	snapshotInterval := flag.Duration("snapshot-interval", time.Minute, "interval of full book snapshots")
+	flushInterval := flag.Duration("flush-interval", time.Second, "interval of writing buffered records to disk")
+	dump := flag.String("dump", "", "print the recording at this path as JSON lines and exit")
+	flag.Parse()
+
+	var err error
+	if *dump != "" {
+		err = dumpRecording(*dump)
+	} else {
+		err = run(recorder.Config{
+			Dir:              *dir,
+			Instruments:      strings.Split(*instruments, ","),
+			TradesInterval:   channels.Interval(*tradesInterval),
+			SnapshotInterval: *snapshotInterval,
+			FlushInterval:    *flushInterval,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run records until the process is interrupted.
+func run(recCfg recorder.Config) error {
+	cfg := deribit.GetConfig()
+	if cfg.ApiKey == "" || cfg.SecretKey == "" {
+		return errors.New("book.{instrument}.raw needs authentication: set DERIBIT_API_KEY and DERIBIT_API_SECRET")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client, err := websocket.Dial(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	recCfg.Logger = cfg.Logger
+	rec := recorder.New(client, recCfg)
+	defer func() {
+		if err := rec.Close(); err != nil {
+			log.Println(err)
+		}
+	}()
+	if err := rec.Start(client); err != nil {
+		return err
+	}
+	log.Printf("recording %v to %s", rec.Channels(), recCfg.Dir)
+
+	<-ctx.Done()
+	return nil
+}
+
+func dumpRecording(path string) error {
+	r, err := recorder.Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// Package channels builds and parses Deribit subscription channel names.
+package channels
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidChannel is returned by Parse for names that match no known
+// channel form.
+var ErrInvalidChannel = errors.New("invalid channel")
+
+// Type identifies one channel form. Channels that can be addressed either by
+// instrument or by kind and currency have a separate Type per form.
+type Type string
+
+const (
+	TypeAnnouncements            Type = "announcements"
+	TypeBlockTradeConfirmations  Type = "block_trade_confirmations"
+	TypeBook                     Type = "book"
+	TypeBookGroup                Type = "book_group"
+	TypeBookDepth                Type = "book_depth"
+	TypeChartTrades              Type = "chart_trades"
+	TypeDeribitPriceIndex        Type = "deribit_price_index"
+	TypeDeribitPriceRanking      Type = "deribit_price_ranking"
+	TypeDeribitPriceStatistics   Type = "deribit_price_statistics"
+	TypeDeribitVolatilityIndex   Type = "deribit_volatility_index"
+	TypeEstimatedExpirationPrice Type = "estimated_expiration_price"
+	TypeIncrementalTicker        Type = "incremental_ticker"
+	TypeInstrumentState          Type = "instrument_state"
+	TypeMarkpriceOptions         Type = "markprice_options"
+	TypePerpetual                Type = "perpetual"
+	TypePlatformState            Type = "platform_state"
+	TypeQuote                    Type = "quote"
+	TypeTicker                   Type = "ticker"
+	TypeTrades                   Type = "trades"
+	TypeTradesByKind             Type = "trades_by_kind"
+	TypeUserAccessLog            Type = "user_access_log"
+	TypeUserChanges              Type = "user_changes"
+	TypeUserChangesByInstrument  Type = "user_changes_by_instrument"
+	TypeUserLock                 Type = "user_lock"
+	TypeUserMMPTrigger           Type = "user_mmp_trigger"
+	TypeUserOrders               Type = "user_orders"
+	TypeUserOrdersByInstrument   Type = "user_orders_by_instrument"
+	TypeUserPortfolio            Type = "user_portfolio"
+	TypeUserTrades               Type = "user_trades"
+	TypeUserTradesByInstrument   Type = "user_trades_by_instrument"
+)
+
+// Interval is the notification interval of a channel.
+type Interval string
+
+const (
+	IntervalRaw   Interval = "raw"
+	Interval100ms Interval = "100ms"
+	IntervalAgg2  Interval = "agg2"
+)
+
+// Kind is an instrument kind used by the kind/currency channel forms.
+type Kind string
+
+const (
+	KindFuture      Kind = "future"
+	KindOption      Kind = "option"
+	KindSpot        Kind = "spot"
+	KindFutureCombo Kind = "future_combo"
+	KindOptionCombo Kind = "option_combo"
+	KindCombo       Kind = "combo"
+	KindAny         Kind = "any"
+)
+
+// Channel is a parsed channel name. Only the fields used by Type are set.
+type Channel struct {
+	Type       Type
+	Instrument string
+	Kind       Kind
+	Currency   string
+	IndexName  string
+	Group      string
+	Depth      int
+	Interval   Interval
+	// Resolution is the chart.trades candle resolution, e.g. "1" or "1D".
+	Resolution string
+}
+
+type spec struct {
+	typ     Type
+	pattern []string
+	private bool
+}
+
+// specs lists every channel form. Placeholders are wrapped in braces; the
+// forms differ in their literal segments or segment count, so at most one
+// matches a name.
+var specs = []spec{
+	{TypeAnnouncements, split("announcements"), false},
+	{TypeBlockTradeConfirmations, split("block_trade_confirmations"), true},
+	{TypeBook, split("book.{instrument}.{interval}"), false},
+	{TypeBookGroup, split("book.{instrument}.{group}.{depth}.{interval}"), false},
+	{TypeBookDepth, split("book.{instrument}.{depth}.{interval}"), false},
+	{TypeChartTrades, split("chart.trades.{instrument}.{resolution}"), false},
+	{TypeDeribitPriceIndex, split("deribit_price_index.{index}"), false},
+	{TypeDeribitPriceRanking, split("deribit_price_ranking.{index}"), false},
+	{TypeDeribitPriceStatistics, split("deribit_price_statistics.{index}"), false},
+	{TypeDeribitVolatilityIndex, split("deribit_volatility_index.{index}"), false},
+	{TypeEstimatedExpirationPrice, split("estimated_expiration_price.{index}"), false},
+	{TypeIncrementalTicker, split("incremental_ticker.{instrument}"), false},
+	{TypeInstrumentState, split("instrument.state.{kind}.{currency}"), false},
+	{TypeMarkpriceOptions, split("markprice.options.{index}"), false},
+	{TypePerpetual, split("perpetual.{instrument}.{interval}"), false},
+	{TypePlatformState, split("platform_state"), false},
+	{TypeQuote, split("quote.{instrument}"), false},
+	{TypeTicker, split("ticker.{instrument}.{interval}"), false},
+	{TypeTrades, split("trades.{instrument}.{interval}"), false},
+	{TypeTradesByKind, split("trades.{kind}.{currency}.{interval}"), false},
+	{TypeUserAccessLog, split("user.access_log"), true},
+	{TypeUserChanges, split("user.changes.{kind}.{currency}.{interval}"), true},
+	{TypeUserChangesByInstrument, split("user.changes.{instrument}.{interval}"), true},
+	{TypeUserLock, split("user.lock"), true},
+	{TypeUserMMPTrigger, split("user.mmp_trigger.{index}"), true},
+	{TypeUserOrders, split("user.orders.{kind}.{currency}.{interval}"), true},
+	{TypeUserOrdersByInstrument, split("user.orders.{instrument}.{interval}"), true},
+	{TypeUserPortfolio, split("user.portfolio.{currency}"), true},
+	{TypeUserTrades, split("user.trades.{kind}.{currency}.{interval}"), true},
+	{TypeUserTradesByInstrument, split("user.trades.{instrument}.{interval}"), true},
+}
+
+var resolutions = map[string]bool{
+	"1": true, "3": true, "5": true, "10": true, "15": true, "30": true,
+	"60": true, "120": true, "180": true, "360": true, "720": true, "1D": true,
+}
+
+func split(pattern string) []string {
+	return strings.Split(pattern, ".")
+}
+
+func lookup(typ Type) (spec, bool) {
+	for _, s := range specs {
+		if s.typ == typ {
+			return s, true
+		}
+	}
+	return spec{}, false
+}
+
+// Parse parses a channel name and validates its intervals, kinds and
+// resolutions.
+func Parse(name string) (Channel, error) {
+	parts := strings.Split(name, ".")
+	for _, s := range specs {
+		if len(s.pattern) != len(parts) || !literalsMatch(s.pattern, parts) {
+			continue
+		}
+		ch := Channel{Type: s.typ}
+		for i, p := range s.pattern {
+			if !isPlaceholder(p) {
+				continue
+			}
+			if err := ch.set(p, parts[i]); err != nil {
+				return Channel{}, fmt.Errorf("%w %q: %v", ErrInvalidChannel, name, err)
+			}
+		}
+		return ch, nil
+	}
+	return Channel{}, fmt.Errorf("%w %q", ErrInvalidChannel, name)
+}
+
+func literalsMatch(pattern []string, parts []string) bool {
+	for i, p := range pattern {
+		if !isPlaceholder(p) && p != parts[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func isPlaceholder(p string) bool {
+	return strings.HasPrefix(p, "{")
+}
+
+func (c *Channel) set(placeholder string, value string) error {
+	if value == "" {
+		return fmt.Errorf("empty %s", strings.Trim(placeholder, "{}"))
+	}
+	switch placeholder {
+	case "{instrument}":
+		c.Instrument = value
+	case "{kind}":
+		switch k := Kind(value); k {
+		case KindFuture, KindOption, KindSpot, KindFutureCombo, KindOptionCombo, KindCombo, KindAny:
+			c.Kind = k
+		default:
+			return fmt.Errorf("unknown kind %q", value)
+		}
+	case "{currency}":
+		c.Currency = value
+	case "{index}":
+		c.IndexName = value
+	case "{group}":
+		c.Group = value
+	case "{depth}":
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth <= 0 {
+			return fmt.Errorf("bad depth %q", value)
+		}
+		c.Depth = depth
+	case "{interval}":
+		switch i := Interval(value); i {
+		case IntervalRaw, Interval100ms, IntervalAgg2:
+			c.Interval = i
+		default:
+			return fmt.Errorf("unknown interval %q", value)
+		}
+	case "{resolution}":
+		if !resolutions[value] {
+			return fmt.Errorf("unknown resolution %q", value)
+		}
+		c.Resolution = value
+	}
+	return nil
+}
+
+func (c Channel) get(placeholder string) string {
+	switch placeholder {
+	case "{instrument}":
+		return c.Instrument
+	case "{kind}":
+		return string(c.Kind)
+	case "{currency}":
+		return c.Currency
+	case "{index}":
+		return c.IndexName
+	case "{group}":
+		return c.Group
+	case "{depth}":
+		return strconv.Itoa(c.Depth)
+	case "{interval}":
+		return string(c.Interval)
+	case "{resolution}":
+		return c.Resolution
+	}
+	return ""
+}
+
+// String returns the channel name to subscribe to, or "" for an unknown Type.
+func (c Channel) String() string {
+	s, ok := lookup(c.Type)
+	if !ok {
+		return ""
+	}
+	parts := make([]string, len(s.pattern))
+	for i, p := range s.pattern {
+		if isPlaceholder(p) {
+			parts[i] = c.get(p)
+		} else {
+			parts[i] = p
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Private reports whether the channel needs private/subscribe.
+func (c Channel) Private() bool {
+	s, ok := lookup(c.Type)
+	return ok && s.private
+}
+
+// Names returns the names of channels, ready for Subscribe.
+func Names(channels ...Channel) []string {
+	names := make([]string, len(channels))
+	for i, c := range channels {
+		names[i] = c.String()
+	}
+	return names
+}
+
+func Announcements() Channel {
+	return Channel{Type: TypeAnnouncements}
+}
+
+func BlockTradeConfirmations() Channel {
+	return Channel{Type: TypeBlockTradeConfirmations}
+}
+
+// Book returns book.{instrument}.{interval}, or the grouped
+// book.{instrument}.{group}.{depth}.{interval} when group is not empty.
+func Book(instrument string, group string, depth int, interval Interval) Channel {
+	if group == "" {
+		return Channel{Type: TypeBook, Instrument: instrument, Interval: interval}
+	}
+	return Channel{Type: TypeBookGroup, Instrument: instrument, Group: group, Depth: depth, Interval: interval}
+}
+
+// BookDepth returns book.{instrument}.{depth}.{interval}, a snapshot of the
+// top depth levels without price grouping.
+func BookDepth(instrument string, depth int, interval Interval) Channel {
+	return Channel{Type: TypeBookDepth, Instrument: instrument, Depth: depth, Interval: interval}
+}
+
+func ChartTrades(instrument string, resolution string) Channel {
+	return Channel{Type: TypeChartTrades, Instrument: instrument, Resolution: resolution}
+}
+
+func DeribitPriceIndex(indexName string) Channel {
+	return Channel{Type: TypeDeribitPriceIndex, IndexName: indexName}
+}
+
+func DeribitPriceRanking(indexName string) Channel {
+	return Channel{Type: TypeDeribitPriceRanking, IndexName: indexName}
+}
+
+func DeribitPriceStatistics(indexName string) Channel {
+	return Channel{Type: TypeDeribitPriceStatistics, IndexName: indexName}
+}
+
+func DeribitVolatilityIndex(indexName string) Channel {
+	return Channel{Type: TypeDeribitVolatilityIndex, IndexName: indexName}
+}
+
+func EstimatedExpirationPrice(indexName string) Channel {
+	return Channel{Type: TypeEstimatedExpirationPrice, IndexName: indexName}
+}
+
+func IncrementalTicker(instrument string) Channel {
+	return Channel{Type: TypeIncrementalTicker, Instrument: instrument}
+}
+
+func InstrumentState(kind Kind, currency string) Channel {
+	return Channel{Type: TypeInstrumentState, Kind: kind, Currency: currency}
+}
+
+func MarkpriceOptions(indexName string) Channel {
+	return Channel{Type: TypeMarkpriceOptions, IndexName: indexName}
+}
+
+func Perpetual(instrument string, interval Interval) Channel {
+	return Channel{Type: TypePerpetual, Instrument: instrument, Interval: interval}
+}
+
+func PlatformState() Channel {
+	return Channel{Type: TypePlatformState}
+}
+
+func Quote(instrument string) Channel {
+	return Channel{Type: TypeQuote, Instrument: instrument}
+}
+
+func Ticker(instrument string, interval Interval) Channel {
+	return Channel{Type: TypeTicker, Instrument: instrument, Interval: interval}
+}
+
+func Trades(instrument string, interval Interval) Channel {
+	return Channel{Type: TypeTrades, Instrument: instrument, Interval: interval}
+}
+
+func TradesByKind(kind Kind, currency string, interval Interval) Channel {
+	return Channel{Type: TypeTradesByKind, Kind: kind, Currency: currency, Interval: interval}
+}
+
+func UserAccessLog() Channel {
+	return Channel{Type: TypeUserAccessLog}
+}
+
+func UserChanges(kind Kind, currency string, interval Interval) Channel {
+	return Channel{Type: TypeUserChanges, Kind: kind, Currency: currency, Interval: interval}
+}
+
+func UserChangesByInstrument(instrument string, interval Interval) Channel {
+	return Channel{Type: TypeUserChangesByInstrument, Instrument: instrument, Interval: interval}
+}
+
+func UserLock() Channel {
+	return Channel{Type: TypeUserLock}
+}
+
+func UserMMPTrigger(indexName string) Channel {
+	return Channel{Type: TypeUserMMPTrigger, IndexName: indexName}
+}
+
+func UserOrders(kind Kind, currency string, interval Interval) Channel {
+	return Channel{Type: TypeUserOrders, Kind: kind, Currency: currency, Interval: interval}
+}
+
+func UserOrdersByInstrument(instrument string, interval Interval) Channel {
+	return Channel{Type: TypeUserOrdersByInstrument, Instrument: instrument, Interval: interval}
+}
+
+func UserPortfolio(currency string) Channel {
+	return Channel{Type: TypeUserPortfolio, Currency: currency}
+}
+
+func UserTrades(kind Kind, currency string, interval Interval) Channel {
+	return Channel{Type: TypeUserTrades, Kind: kind, Currency: currency, Interval: interval}
+}
+
+func UserTradesByInstrument(instrument string, interval Interval) Channel {
+	return Channel{Type: TypeUserTradesByInstrument, Instrument: instrument, Interval: interval}
+}
@@ -0,0 +1,87 @@
+package channels
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		channel Channel
+		name    string
+		private bool
+	}{
+		{Announcements(), "announcements", false},
+		{BlockTradeConfirmations(), "block_trade_confirmations", true},
+		{Book("BTC-PERPETUAL", "", 0, IntervalRaw), "book.BTC-PERPETUAL.raw", false},
+		{Book("BTC-PERPETUAL", "", 0, Interval100ms), "book.BTC-PERPETUAL.100ms", false},
+		{Book("ETH-PERPETUAL", "none", 10, Interval100ms), "book.ETH-PERPETUAL.none.10.100ms", false},
+		{Book("ETH-PERPETUAL", "5", 20, IntervalAgg2), "book.ETH-PERPETUAL.5.20.agg2", false},
+		{BookDepth("BTC-PERPETUAL", 10, Interval100ms), "book.BTC-PERPETUAL.10.100ms", false},
+		{ChartTrades("BTC-PERPETUAL", "1D"), "chart.trades.BTC-PERPETUAL.1D", false},
+		{DeribitPriceIndex("btc_usd"), "deribit_price_index.btc_usd", false},
+		{DeribitPriceRanking("btc_usd"), "deribit_price_ranking.btc_usd", false},
+		{DeribitPriceStatistics("btc_usd"), "deribit_price_statistics.btc_usd", false},
+		{DeribitVolatilityIndex("btc_usd"), "deribit_volatility_index.btc_usd", false},
+		{EstimatedExpirationPrice("btc_usd"), "estimated_expiration_price.btc_usd", false},
+		{IncrementalTicker("BTC-PERPETUAL"), "incremental_ticker.BTC-PERPETUAL", false},
+		{InstrumentState(KindOption, "BTC"), "instrument.state.option.BTC", false},
+		{MarkpriceOptions("btc_usd"), "markprice.options.btc_usd", false},
+		{Perpetual("BTC-PERPETUAL", IntervalRaw), "perpetual.BTC-PERPETUAL.raw", false},
+		{PlatformState(), "platform_state", false},
+		{Quote("BTC-PERPETUAL"), "quote.BTC-PERPETUAL", false},
+		{Ticker("BTC-29MAR24-60000-C", Interval100ms), "ticker.BTC-29MAR24-60000-C.100ms", false},
+		{Trades("BTC-PERPETUAL", IntervalRaw), "trades.BTC-PERPETUAL.raw", false},
+		{TradesByKind(KindFuture, "BTC", Interval100ms), "trades.future.BTC.100ms", false},
+		{UserAccessLog(), "user.access_log", true},
+		{UserChanges(KindAny, "any", IntervalRaw), "user.changes.any.any.raw", true},
+		{UserChangesByInstrument("BTC-PERPETUAL", IntervalRaw), "user.changes.BTC-PERPETUAL.raw", true},
+		{UserLock(), "user.lock", true},
+		{UserMMPTrigger("btc_usd"), "user.mmp_trigger.btc_usd", true},
+		{UserOrders(KindFuture, "BTC", Interval100ms), "user.orders.future.BTC.100ms", true},
+		{UserOrdersByInstrument("BTC-PERPETUAL", IntervalRaw), "user.orders.BTC-PERPETUAL.raw", true},
+		{UserPortfolio("btc"), "user.portfolio.btc", true},
+		{UserTrades(KindOptionCombo, "ETH", IntervalRaw), "user.trades.option_combo.ETH.raw", true},
+		{UserTradesByInstrument("BTC-PERPETUAL", Interval100ms), "user.trades.BTC-PERPETUAL.100ms", true},
+	}
+
+	covered := make(map[Type]bool)
+	for _, tt := range tests {
+		assert.Equal(t, tt.name, tt.channel.String())
+		parsed, err := Parse(tt.name)
+		if assert.NoError(t, err, tt.name) {
+			assert.Equal(t, tt.channel, parsed, tt.name)
+			assert.Equal(t, tt.private, parsed.Private(), tt.name)
+		}
+		covered[tt.channel.Type] = true
+	}
+	for _, s := range specs {
+		assert.True(t, covered[s.typ], "no round-trip test for %s", s.typ)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"tickers.BTC-PERPETUAL.raw",
+		"ticker.BTC-PERPETUAL",
+		"ticker.BTC-PERPETUAL.100s",
+		"ticker..raw",
+		"book.BTC-PERPETUAL.none.ten.100ms",
+		"book.BTC-PERPETUAL.none.0.100ms",
+		"trades.futures.BTC.raw",
+		"chart.trades.BTC-PERPETUAL.2",
+		"user.portfolio",
+	} {
+		_, err := Parse(name)
+		assert.True(t, errors.Is(err, ErrInvalidChannel), "%q: %v", name, err)
+	}
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t,
+		[]string{"ticker.BTC-PERPETUAL.raw", "user.portfolio.btc"},
+		Names(Ticker("BTC-PERPETUAL", IntervalRaw), UserPortfolio("btc")))
+}
@@ -0,0 +1,84 @@
+package deribit
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes exponentially growing delays with random jitter.
+type Backoff struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration `json:"initial_interval"`
+	// MaxInterval caps a single delay.
+	MaxInterval time.Duration `json:"max_interval"`
+	// Multiplier grows the delay after every attempt.
+	Multiplier float64 `json:"multiplier"`
+	// Jitter is the random fraction applied to each delay: 0.2 spreads a
+	// delay d over [0.8d, 1.2d].
+	Jitter float64 `json:"jitter"`
+}
+
+// Duration returns the delay to wait after the given attempt, counting from 0.
+func (b Backoff) Duration(attempt int) time.Duration {
+	if b.InitialInterval <= 0 {
+		return 0
+	}
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	d := float64(b.InitialInterval) * math.Pow(multiplier, float64(attempt))
+	if b.MaxInterval > 0 && d > float64(b.MaxInterval) {
+		d = float64(b.MaxInterval)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(d)
+}
+
+// ReconnectPolicy controls how the WebSocket client re-establishes a dropped
+// connection.
+type ReconnectPolicy struct {
+	Backoff
+	// MaxElapsedTime bounds the time spent on one reconnection cycle. Zero
+	// retries forever.
+	MaxElapsedTime time.Duration `json:"max_elapsed_time"`
+	// DialTimeout bounds the initial connection attempts made by Dial, so a
+	// client that never connects fails instead of retrying forever. Zero
+	// means 30 seconds; a negative value retries until the Dial context is
+	// done.
+	DialTimeout time.Duration `json:"dial_timeout"`
+	// OnGiveUp, if set, is called with the last error once MaxElapsedTime
+	// is exceeded and the client stops reconnecting.
+	OnGiveUp func(err error) `json:"-"`
+}
+
+const defaultDialTimeout = 30 * time.Second
+
+// InitialMaxElapsedTime returns the bound on the initial connection cycle
+// derived from DialTimeout, zero meaning unbounded.
+func (p *ReconnectPolicy) InitialMaxElapsedTime() time.Duration {
+	timeout := p.DialTimeout
+	switch {
+	case timeout == 0:
+		timeout = defaultDialTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+	return timeout
+}
+
+// DefaultReconnectPolicy reconnects forever, starting at one second and
+// backing off to one minute.
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		Backoff: Backoff{
+			InitialInterval: time.Second,
+			MaxInterval:     time.Minute,
+			Multiplier:      2,
+			Jitter:          0.2,
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package deribit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Duration(t *testing.T) {
+	b := Backoff{
+		InitialInterval: 100 * time.Millisecond,
+		MaxInterval:     time.Second,
+		Multiplier:      2,
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{2, 400 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{4, time.Second},
+		{20, time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.Duration(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	b := Backoff{
+		InitialInterval: time.Second,
+		Multiplier:      1,
+		Jitter:          0.25,
+	}
+
+	for i := 0; i < 1000; i++ {
+		d := b.Duration(i)
+		assert.GreaterOrEqual(t, d, 750*time.Millisecond)
+		assert.LessOrEqual(t, d, 1250*time.Millisecond)
+	}
+}
+
+func TestBackoff_Zero(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Backoff{}.Duration(3))
+}
+
+func TestReconnectPolicy_InitialMaxElapsedTime(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{0, defaultDialTimeout},
+		{time.Second, time.Second},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		p := &ReconnectPolicy{DialTimeout: tt.timeout}
+		assert.Equal(t, tt.want, p.InitialMaxElapsedTime(), tt.timeout)
+	}
+}
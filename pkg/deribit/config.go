@@ -2,9 +2,12 @@ package deribit
 
 import (
 	"context"
+	"crypto/tls"
 	"github.com/sirupsen/logrus"
+	"net/http"
 	"os"
 	"strconv"
+	"time"
 )
 
 const (
@@ -15,6 +18,9 @@ const (
 )
 
 const (
+	// MaxTryTimes was the fixed number of connection attempts.
+	//
+	// Deprecated: the WebSocket client follows Configuration.Reconnect.
 	MaxTryTimes = 10000
 )
 
@@ -36,6 +42,59 @@ type Configuration struct {
 	WSBaseURL     string `json:"ws_base_url"`
 	RestBaseURL   string `json:"rest_base_url"`
 	Logger        *logrus.Logger
+
+	// UnsubscribeOnClose makes the WebSocket client's Close drop all
+	// subscriptions on the server before disconnecting.
+	UnsubscribeOnClose bool `json:"unsubscribe_on_close"`
+	// LogoutOnClose makes the WebSocket client's Close log out of an
+	// authenticated session before disconnecting.
+	LogoutOnClose bool `json:"logout_on_close"`
+	// Reconnect controls connection retries of the WebSocket client. Nil
+	// means DefaultReconnectPolicy.
+	Reconnect *ReconnectPolicy `json:"reconnect"`
+	// SignatureAuth authenticates with the client_signature grant, so the
+	// secret key itself is never sent to the server. The REST client signs
+	// every private request with a deri-hmac-sha256 Authorization header
+	// instead of using a bearer token.
+	SignatureAuth bool `json:"signature_auth"`
+	// Dispatch configures the queues that decouple subscription listeners
+	// from the WebSocket read loop. Nil means DefaultDispatchConfig.
+	Dispatch *DispatchConfig `json:"dispatch"`
+	// HeartbeatInterval is the interval the server is asked to send
+	// heartbeats at. The WebSocket client treats a connection that stays
+	// silent for two intervals as dead. Zero means 30 seconds.
+	HeartbeatInterval time.Duration `json:"heartbeat_interval"`
+	// Retry controls retries of idempotent calls in both clients. Nil
+	// means DefaultRetryPolicy; MaxAttempts 1 disables retries.
+	Retry *RetryPolicy `json:"retry"`
+	// RateLimiter, if set, is consulted before every call of both clients.
+	// Share one ratelimit.Limiter between all clients of an account.
+	RateLimiter RateLimiter `json:"-"`
+
+	// HTTPTimeout bounds every REST request, including reading the
+	// response. Zero means 30 seconds; a negative value disables it.
+	HTTPTimeout time.Duration `json:"http_timeout"`
+	// Transport, if set, carries the requests of both clients instead of
+	// a clone of http.DefaultTransport. ProxyURL and TLSConfig only apply
+	// to the default transport.
+	Transport http.RoundTripper `json:"-"`
+	// ProxyURL routes requests through an HTTP or SOCKS5 proxy, for
+	// example "http://proxy:3128". Empty means the proxy of the
+	// environment.
+	ProxyURL string `json:"proxy_url"`
+	// TLSConfig replaces the TLS configuration of the default transport.
+	TLSConfig *tls.Config `json:"-"`
+	// UserAgent, if set, is sent with every REST request and the
+	// WebSocket handshake.
+	UserAgent string `json:"user_agent"`
+}
+
+// RateLimiter throttles API calls. ratelimit.Limiter implements it.
+type RateLimiter interface {
+	// Wait blocks until method may be called.
+	Wait(ctx context.Context, method string) error
+	// Observe reports the outcome of a call.
+	Observe(method string, err error)
 }
 
 func GetConfig() *Configuration {
@@ -0,0 +1,61 @@
+package deribit
+
+import "github.com/xingxing/deribit-api/pkg/channels"
+
+// OverflowPolicy decides what a full notification queue does with a new
+// event.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the connection's read loop wait for room. No event
+	// is lost, but RPC responses and heartbeats wait as well.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued event.
+	OverflowDropOldest
+	// OverflowConflate keeps only the latest undelivered event, for channels
+	// such as tickers where only the current value matters.
+	OverflowConflate
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowConflate:
+		return "conflate"
+	}
+	return "unknown"
+}
+
+// DispatchConfig configures the per-channel notification queues of the
+// WebSocket client.
+type DispatchConfig struct {
+	// QueueSize is the capacity of each channel's queue. Zero means 1024.
+	QueueSize int `json:"queue_size"`
+	// Policy applies to channel types missing from Policies.
+	Policy OverflowPolicy `json:"policy"`
+	// Policies overrides Policy per channel type.
+	Policies map[channels.Type]OverflowPolicy `json:"policies"`
+}
+
+// DefaultDispatchConfig blocks on full queues, except for tickers, which are
+// conflated.
+func DefaultDispatchConfig() *DispatchConfig {
+	return &DispatchConfig{
+		QueueSize: 1024,
+		Policy:    OverflowBlock,
+		Policies: map[channels.Type]OverflowPolicy{
+			channels.TypeTicker: OverflowConflate,
+		},
+	}
+}
+
+// PolicyFor returns the overflow policy for a channel type.
+func (d *DispatchConfig) PolicyFor(typ channels.Type) OverflowPolicy {
+	if p, ok := d.Policies[typ]; ok {
+		return p
+	}
+	return d.Policy
+}
@@ -0,0 +1,78 @@
+package deribit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Deribit API error codes the clients branch on.
+// See https://docs.deribit.com/#rpc-error-codes
+const (
+	CodeOrderNotFound      = 10004
+	CodePriceTooLow        = 10005
+	CodePriceTooLow4Idx    = 10006
+	CodePriceTooHigh       = 10007
+	CodePriceTooHigh4Idx   = 10008
+	CodeNotEnoughFunds     = 10009
+	CodeTooManyRequests    = 10028
+	CodePostOnlyReject     = 11054
+	CodeInvalidCredentials = 13004
+	CodeInvalidToken       = 13009
+)
+
+// APIError is an error returned by the Deribit API, over REST or WebSocket.
+type APIError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *APIError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("API error %d: %s %s", e.Code, e.Message, string(e.Data))
+	}
+	return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+}
+
+// Is reports whether target is an *APIError of the same kind, so the
+// sentinels below can be used with errors.Is. Codes that only differ in the
+// reference price (e.g. price_too_low and price_too_low4idx) share a kind.
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	if !ok {
+		return false
+	}
+	return errorKind(e.Code) == errorKind(t.Code)
+}
+
+func errorKind(code int) int {
+	switch code {
+	case CodePriceTooLow4Idx:
+		return CodePriceTooLow
+	case CodePriceTooHigh4Idx:
+		return CodePriceTooHigh
+	}
+	return code
+}
+
+// Sentinels for use with errors.Is.
+var (
+	ErrOrderNotFound      = &APIError{Code: CodeOrderNotFound, Message: "order_not_found"}
+	ErrPriceTooLow        = &APIError{Code: CodePriceTooLow, Message: "price_too_low"}
+	ErrPriceTooHigh       = &APIError{Code: CodePriceTooHigh, Message: "price_too_high"}
+	ErrNotEnoughFunds     = &APIError{Code: CodeNotEnoughFunds, Message: "not_enough_funds"}
+	ErrTooManyRequests    = &APIError{Code: CodeTooManyRequests, Message: "too_many_requests"}
+	ErrPostOnlyReject     = &APIError{Code: CodePostOnlyReject, Message: "post_only_reject"}
+	ErrInvalidCredentials = &APIError{Code: CodeInvalidCredentials, Message: "invalid_credentials"}
+	ErrInvalidToken       = &APIError{Code: CodeInvalidToken, Message: "unauthorized"}
+)
+
+// AsAPIError returns the *APIError in err's chain, if any.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
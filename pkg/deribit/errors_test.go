@@ -0,0 +1,52 @@
+package deribit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"same code", &APIError{Code: 10009, Message: "not_enough_funds"}, ErrNotEnoughFunds, true},
+		{"wrapped", fmt.Errorf("buy: %w", &APIError{Code: 10028}), ErrTooManyRequests, true},
+		{"index variant low", &APIError{Code: CodePriceTooLow4Idx}, ErrPriceTooLow, true},
+		{"index variant high", &APIError{Code: CodePriceTooHigh4Idx}, ErrPriceTooHigh, true},
+		{"different code", &APIError{Code: 10004}, ErrPostOnlyReject, false},
+		{"low is not high", &APIError{Code: CodePriceTooLow}, ErrPriceTooHigh, false},
+		{"not an api error", errors.New("API error 13009: unauthorized"), ErrInvalidToken, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, errors.Is(tt.err, tt.target))
+		})
+	}
+}
+
+func TestAPIError_Unmarshal(t *testing.T) {
+	var apiErr APIError
+	data := `{"code":10007,"message":"price_too_high","data":{"param":"price","reason":"must be less than 99000"}}`
+	assert.NoError(t, json.Unmarshal([]byte(data), &apiErr))
+	assert.Equal(t, CodePriceTooHigh, apiErr.Code)
+	assert.Equal(t, "price_too_high", apiErr.Message)
+	assert.JSONEq(t, `{"param":"price","reason":"must be less than 99000"}`, string(apiErr.Data))
+	assert.Contains(t, apiErr.Error(), "API error 10007: price_too_high")
+}
+
+func TestAsAPIError(t *testing.T) {
+	apiErr, ok := AsAPIError(fmt.Errorf("call: %w", &APIError{Code: 11054, Message: "post_only_reject"}))
+	assert.True(t, ok)
+	assert.Equal(t, CodePostOnlyReject, apiErr.Code)
+
+	_, ok = AsAPIError(errors.New("boom"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package deribit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// HTTPClient returns an HTTP client built from the HTTP options of c. An
+// invalid ProxyURL fails every request that goes through the client.
+func (c *Configuration) HTTPClient() *http.Client {
+	timeout := c.HTTPTimeout
+	switch {
+	case timeout == 0:
+		timeout = defaultHTTPTimeout
+	case timeout < 0:
+		timeout = 0
+	}
+
+	transport := c.Transport
+	if transport == nil {
+		transport = c.defaultTransport()
+	}
+	if c.UserAgent != "" {
+		transport = &userAgentTransport{base: transport, userAgent: c.UserAgent}
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// HTTPHeader returns the headers of the WebSocket handshake.
+func (c *Configuration) HTTPHeader() http.Header {
+	header := http.Header{}
+	if c.UserAgent != "" {
+		header.Set("User-Agent", c.UserAgent)
+	}
+	return header
+}
+
+func (c *Configuration) defaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			err = fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = func(*http.Request) (*url.URL, error) {
+			return proxyURL, err
+		}
+	}
+	if c.TLSConfig != nil {
+		transport.TLSClientConfig = c.TLSConfig.Clone()
+	}
+	return transport
+}
+
+// userAgentTransport sets the User-Agent header of requests that have none.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// A RoundTripper must not modify the caller's request.
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
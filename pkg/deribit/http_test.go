@@ -0,0 +1,102 @@
+package deribit
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPClient_Timeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{"default", 0, 30 * time.Second},
+		{"custom", 5 * time.Second, 5 * time.Second},
+		{"disabled", -1, 0},
+	}
+	for _, tt := range tests {
+		cfg := &Configuration{HTTPTimeout: tt.timeout}
+		assert.Equal(t, tt.want, cfg.HTTPClient().Timeout, tt.name)
+	}
+}
+
+func TestHTTPClient_UserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	client := (&Configuration{UserAgent: "bot/1.0"}).HTTPClient()
+	_, err := client.Get(server.URL)
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("User-Agent", "explicit")
+	_, err = client.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, "explicit", req.Header.Get("User-Agent"))
+
+	assert.Equal(t, []string{"bot/1.0", "explicit"}, userAgents)
+	assert.Equal(t, "bot/1.0", (&Configuration{UserAgent: "bot/1.0"}).HTTPHeader().Get("User-Agent"))
+	assert.Empty(t, (&Configuration{}).HTTPHeader())
+}
+
+func TestHTTPClient_Transport(t *testing.T) {
+	var used bool
+	cfg := &Configuration{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			used = true
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+		}),
+		// Proxy and TLS options only apply to the default transport.
+		ProxyURL: "http://127.0.0.1:1",
+	}
+	resp, err := cfg.HTTPClient().Get("http://example.invalid/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, used)
+}
+
+func TestHTTPClient_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	_, err := (&Configuration{ProxyURL: proxy.URL}).HTTPClient().Get("http://deribit.invalid/api/v2/public/test")
+	require.NoError(t, err)
+	assert.Equal(t, "http://deribit.invalid/api/v2/public/test", proxied)
+
+	_, err = (&Configuration{ProxyURL: "://bad"}).HTTPClient().Get("http://deribit.invalid/")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid proxy URL")
+	}
+}
+
+func TestHTTPClient_TLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The test certificate is not trusted by default.
+	_, err := (&Configuration{}).HTTPClient().Get(server.URL)
+	assert.Error(t, err)
+
+	certPool := server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	_, err = (&Configuration{TLSConfig: &tls.Config{RootCAs: certPool}}).HTTPClient().Get(server.URL)
+	assert.NoError(t, err)
+}
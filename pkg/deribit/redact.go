@@ -0,0 +1,16 @@
+package deribit
+
+import "regexp"
+
+var (
+	secretParam  = regexp.MustCompile(`((?:client_secret|access_token|refresh_token|signature)(?:=|"\s*:\s*"))[^&"\s]+`)
+	secretHeader = regexp.MustCompile(`((?:Bearer|sig=)\s*)[^,\s"]+`)
+)
+
+// Redact masks client secrets, tokens and signatures in s, which may be a
+// URL, a query string, a JSON document or an Authorization header. It is
+// applied to everything the clients log.
+func Redact(s string) string {
+	s = secretParam.ReplaceAllString(s, "${1}***")
+	return secretHeader.ReplaceAllString(s, "${1}***")
+}
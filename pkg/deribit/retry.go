@@ -0,0 +1,139 @@
+package deribit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the clients retry failed calls. Only idempotent
+// methods are retried; see IsIdempotent and IsRetryable.
+type RetryPolicy struct {
+	Backoff
+	// MaxAttempts bounds the attempts per call, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int `json:"max_attempts"`
+	// Idempotent, if set, replaces IsIdempotent to decide which methods may
+	// be sent again.
+	Idempotent func(method string) bool `json:"-"`
+}
+
+// DefaultRetryPolicy makes up to three attempts, backing off from 200ms.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		Backoff: Backoff{
+			InitialInterval: 200 * time.Millisecond,
+			MaxInterval:     5 * time.Second,
+			Multiplier:      2,
+			Jitter:          0.2,
+		},
+		MaxAttempts: 3,
+	}
+}
+
+// IsIdempotent reports whether method can be sent again without changing
+// its effect: public methods and the private get_* queries. Order entry
+// such as private/buy, private/sell and private/edit is never idempotent.
+func IsIdempotent(method string) bool {
+	if strings.HasPrefix(method, "public/") {
+		return true
+	}
+	return strings.HasPrefix(method, "private/get_")
+}
+
+// HTTPError is returned by the REST client for a response with an error
+// status and no JSON-RPC error body, such as a 502 from a proxy.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return "HTTP error: " + e.Status
+}
+
+// permanentError marks an error that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not retryable. RetryPolicy.Do returns the
+// unwrapped err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable reports whether err is transient: too_many_requests, an HTTP
+// 429 or 5xx status, or a network error. Other API errors, errors marked
+// with Permanent and context cancellation are not.
+func IsRetryable(err error) bool {
+	var permanent *permanentError
+	if err == nil || errors.As(err, &permanent) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if apiErr, ok := AsAPIError(err); ok {
+		return apiErr.Code == CodeTooManyRequests
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	return true
+}
+
+// Retries reports whether calls to method are retried under p.
+func (p *RetryPolicy) Retries(method string) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+	if p.Idempotent != nil {
+		return p.Idempotent(method)
+	}
+	return IsIdempotent(method)
+}
+
+// Do calls fn until it succeeds or fails with an error that is not
+// retryable, backing off between attempts. fn is called once if p does not
+// retry method.
+func (p *RetryPolicy) Do(ctx context.Context, method string, fn func() error) error {
+	if !p.Retries(method) {
+		return unwrapPermanent(fn())
+	}
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if !IsRetryable(err) || attempt+1 >= p.MaxAttempts {
+			return unwrapPermanent(err)
+		}
+		if wErr := p.Wait(ctx, attempt); wErr != nil {
+			return err
+		}
+	}
+}
+
+func unwrapPermanent(err error) error {
+	if permanent, ok := err.(*permanentError); ok {
+		return permanent.err
+	}
+	return err
+}
+
+// Wait sleeps for the backoff delay after the given attempt, counting from
+// 0. It returns early with ctx's error when ctx is done.
+func (p *RetryPolicy) Wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(p.Duration(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,117 @@
+package deribit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsIdempotent(t *testing.T) {
+	tests := map[string]bool{
+		"public/get_instruments":                true,
+		"public/ticker":                         true,
+		"private/get_open_orders_by_instrument": true,
+		"private/buy":                           false,
+		"private/sell":                          false,
+		"private/edit":                          false,
+		"private/cancel":                        false,
+	}
+	for method, want := range tests {
+		assert.Equal(t, want, IsIdempotent(method), method)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("connection reset by peer"), true},
+		{ErrTooManyRequests, true},
+		{fmt.Errorf("wrapped: %w", &APIError{Code: CodeTooManyRequests}), true},
+		{ErrNotEnoughFunds, false},
+		{&HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, true},
+		{&HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, true},
+		{&HTTPError{StatusCode: 404, Status: "404 Not Found"}, false},
+		{context.Canceled, false},
+		{fmt.Errorf("request failed: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsRetryable(tt.err), "%v", tt.err)
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	p := &RetryPolicy{Backoff: Backoff{InitialInterval: time.Millisecond}, MaxAttempts: 3}
+
+	calls := 0
+	err := p.Do(context.Background(), "public/get_instruments", func() error {
+		calls++
+		if calls < 3 {
+			return &HTTPError{StatusCode: 502}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), "public/get_instruments", func() error {
+		calls++
+		return ErrTooManyRequests
+	})
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), "private/buy", func() error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), "public/ticker", func() error {
+		calls++
+		return ErrNotEnoughFunds
+	})
+	assert.ErrorIs(t, err, ErrNotEnoughFunds)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	sentinel := errors.New("authentication is required")
+	err = p.Do(context.Background(), "private/get_position", func() error {
+		calls++
+		return Permanent(sentinel)
+	})
+	assert.Equal(t, sentinel, err)
+	assert.Equal(t, 1, calls)
+
+	var nilPolicy *RetryPolicy
+	calls = 0
+	_ = nilPolicy.Do(context.Background(), "public/ticker", func() error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryPolicy_DoStopsOnCancel(t *testing.T) {
+	p := &RetryPolicy{Backoff: Backoff{InitialInterval: time.Hour}, MaxAttempts: 3}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := p.Do(ctx, "public/ticker", func() error {
+		calls++
+		return errors.New("connection reset by peer")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,44 @@
+package deribit
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signature returns the hex encoded HMAC-SHA256 of
+// "timestamp\nnonce\ndata" keyed with secret, as used by Deribit's
+// client_signature grant.
+func Signature(secret string, timestamp int64, nonce string, data string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "\n" + nonce + "\n" + data))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Nonce returns a random string for use in signed requests.
+func Nonce() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestSignature returns the signature of an HTTP request for the
+// deri-hmac-sha256 Authorization scheme. uri is the request path including
+// the query string and body the raw request body.
+func RequestSignature(secret string, timestamp int64, nonce string, method string, uri string, body string) string {
+	return Signature(secret, timestamp, nonce, strings.ToUpper(method)+"\n"+uri+"\n"+body+"\n")
+}
+
+// AuthorizationHeader returns a deri-hmac-sha256 Authorization header value
+// for an HTTP request, signed now with a fresh nonce.
+func AuthorizationHeader(clientID string, secret string, method string, uri string, body string) string {
+	timestamp := time.Now().UnixMilli()
+	nonce := Nonce()
+	return fmt.Sprintf("deri-hmac-sha256 id=%s,ts=%d,sig=%s,nonce=%s",
+		clientID, timestamp, RequestSignature(secret, timestamp, nonce, method, uri, body), nonce)
+}
@@ -0,0 +1,54 @@
+package deribit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignature(t *testing.T) {
+	// HMAC-SHA256("secret", "1576074319000\n1ahqbh\n")
+	sig := Signature("secret", 1576074319000, "1ahqbh", "")
+	assert.Equal(t, "ad02531b5a21340adc6bc765e4a4dddc37d9f63a96f6a5a53690d25811d9b71b", sig)
+}
+
+func TestNonce(t *testing.T) {
+	a, b := Nonce(), Nonce()
+	assert.Len(t, a, 16)
+	assert.NotEqual(t, a, b)
+}
+
+func TestRequestSignature(t *testing.T) {
+	// HMAC-SHA256("secret", "1576074319000\n1ahqbh\nGET\n/api/v2/private/get_account_summary?currency=BTC\n\n")
+	sig := RequestSignature("secret", 1576074319000, "1ahqbh", "get", "/api/v2/private/get_account_summary?currency=BTC", "")
+	assert.Equal(t, Signature("secret", 1576074319000, "1ahqbh", "GET\n/api/v2/private/get_account_summary?currency=BTC\n\n"), sig)
+	assert.Len(t, sig, 64)
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	header := AuthorizationHeader("client", "secret", "GET", "/api/v2/private/buy", "")
+	assert.Regexp(t, `^deri-hmac-sha256 id=client,ts=\d+,sig=[0-9a-f]{64},nonce=[0-9a-f]{16}$`, header)
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			"https://test.deribit.com/api/v2/public/auth?client_id=abc&client_secret=s3cr3t&grant_type=client_credentials",
+			"https://test.deribit.com/api/v2/public/auth?client_id=abc&client_secret=***&grant_type=client_credentials",
+		},
+		{
+			`{"result":{"access_token":"tok","refresh_token": "ref","expires_in":900}}`,
+			`{"result":{"access_token":"***","refresh_token": "***","expires_in":900}}`,
+		},
+		{"Bearer tok", "Bearer ***"},
+		{"deri-hmac-sha256 id=abc,ts=1,sig=0123abcd,nonce=xyz", "deri-hmac-sha256 id=abc,ts=1,sig=***,nonce=xyz"},
+		{`{"signature":"0123abcd","nonce":"xyz"}`, `{"signature":"***","nonce":"xyz"}`},
+		{"book.BTC-PERPETUAL.raw", "book.BTC-PERPETUAL.raw"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Redact(tt.in))
+	}
+}
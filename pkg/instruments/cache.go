@@ -0,0 +1,123 @@
+// Package instruments caches instrument metadata and rounds order prices
+// and amounts to what each instrument accepts.
+package instruments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// ErrUnknownInstrument is returned for an instrument the server does not
+// list.
+var ErrUnknownInstrument = errors.New("unknown instrument")
+
+// Source lists instruments. Both the REST and the WebSocket client
+// implement it.
+type Source interface {
+	GetInstrumentsContext(ctx context.Context, params *models.GetInstrumentsParams) ([]models.Instrument, error)
+}
+
+// minReload is the shortest time between the loads of a Cache that are
+// caused by unknown names.
+const minReload = 10 * time.Second
+
+// Cache holds instrument metadata fetched from a Source. It loads all
+// instruments on first use and again when asked for an instrument it does
+// not know, such as a newly listed option, at most once every ten seconds
+// so that unknown names do not exhaust the rate limit. It is safe for
+// concurrent use.
+type Cache struct {
+	src Source
+
+	// loadMu serializes loads and guards loadedAt; mu guards byName.
+	loadMu   sync.Mutex
+	loadedAt time.Time
+	mu       sync.RWMutex
+	byName   map[string]models.Instrument
+}
+
+// NewCache returns an empty cache over src.
+func NewCache(src Source) *Cache {
+	return &Cache{src: src}
+}
+
+// Refresh reloads the active instruments of all currencies.
+func (c *Cache) Refresh(ctx context.Context) error {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	return c.load(ctx)
+}
+
+func (c *Cache) load(ctx context.Context) error {
+	list, err := c.src.GetInstrumentsContext(ctx, &models.GetInstrumentsParams{Currency: "any"})
+	if err != nil {
+		return fmt.Errorf("failed to load instruments: %w", err)
+	}
+	byName := make(map[string]models.Instrument, len(list))
+	for _, instrument := range list {
+		byName[instrument.InstrumentName] = instrument
+	}
+
+	c.mu.Lock()
+	c.byName = byName
+	c.mu.Unlock()
+	c.loadedAt = time.Now()
+	return nil
+}
+
+func (c *Cache) lookup(name string) (models.Instrument, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	instrument, ok := c.byName[name]
+	return instrument, ok
+}
+
+// Get returns the metadata of the named instrument. A name that is not
+// cached fails with ErrUnknownInstrument without reloading if the last load
+// was less than ten seconds ago.
+func (c *Cache) Get(ctx context.Context, name string) (models.Instrument, error) {
+	if instrument, ok := c.lookup(name); ok {
+		return instrument, nil
+	}
+
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	// Another caller may have loaded it while we waited.
+	if instrument, ok := c.lookup(name); ok {
+		return instrument, nil
+	}
+	if !c.loadedAt.IsZero() && time.Since(c.loadedAt) < minReload {
+		return models.Instrument{}, fmt.Errorf("%w: %s", ErrUnknownInstrument, name)
+	}
+	if err := c.load(ctx); err != nil {
+		return models.Instrument{}, err
+	}
+	if instrument, ok := c.lookup(name); ok {
+		return instrument, nil
+	}
+	return models.Instrument{}, fmt.Errorf("%w: %s", ErrUnknownInstrument, name)
+}
+
+// Prepare rounds price to the instrument's tick and amount down to its
+// amount step, then validates the result. A zero price, for market orders,
+// is left alone.
+func (c *Cache) Prepare(ctx context.Context, name string, price decimal.Decimal, amount decimal.Decimal) (decimal.Decimal, decimal.Decimal, error) {
+	instrument, err := c.Get(ctx, name)
+	if err != nil {
+		return price, amount, err
+	}
+	if !price.IsZero() {
+		price = RoundPrice(instrument, price)
+	}
+	amount = RoundAmount(instrument, amount)
+	return price, amount, Validate(instrument, price, amount)
+}
@@ -0,0 +1,74 @@
+package instruments
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type fakeSource struct {
+	loads       atomic.Int32
+	instruments []models.Instrument
+}
+
+func (s *fakeSource) GetInstrumentsContext(_ context.Context, params *models.GetInstrumentsParams) ([]models.Instrument, error) {
+	s.loads.Add(1)
+	if params.Currency != "any" {
+		return nil, errors.New("unexpected currency " + params.Currency)
+	}
+	return s.instruments, nil
+}
+
+func TestCache_Get(t *testing.T) {
+	src := &fakeSource{instruments: []models.Instrument{btcPerpetual, ethPerpetual}}
+	cache := NewCache(src)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instrument, err := cache.Get(context.Background(), "ETH-PERPETUAL")
+			assert.NoError(t, err)
+			assert.Equal(t, 0.05, instrument.TickSize)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), src.loads.Load())
+
+	// Unknown names right after a load fail without fetching again.
+	for i := 0; i < 5; i++ {
+		_, err := cache.Get(context.Background(), "BTC-27DEC30-100000-C")
+		assert.True(t, errors.Is(err, ErrUnknownInstrument))
+	}
+	assert.Equal(t, int32(1), src.loads.Load())
+
+	// Once the last load is old enough, an unknown name reloads, which
+	// picks up new listings.
+	src.instruments = append(src.instruments, btcOption)
+	cache.loadedAt = cache.loadedAt.Add(-minReload)
+	_, err := cache.Get(context.Background(), "BTC-27DEC30-100000-C")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), src.loads.Load())
+
+	_, err = cache.Get(context.Background(), "BTC-PERPETUAL-TYPO")
+	assert.True(t, errors.Is(err, ErrUnknownInstrument))
+	assert.Equal(t, int32(2), src.loads.Load())
+}
+
+func TestCache_Prepare(t *testing.T) {
+	cache := NewCache(&fakeSource{instruments: []models.Instrument{btcPerpetual, btcOption}})
+
+	price, amount, err := cache.Prepare(context.Background(), "BTC-PERPETUAL", d("65000.3"), d("105"))
+	assert.NoError(t, err)
+	assert.True(t, d("65000.5").Equal(price), price.String())
+	assert.True(t, d("100").Equal(amount), amount.String())
+
+	_, _, err = cache.Prepare(context.Background(), "BTC-27DEC30-100000-C", d("0.0123"), d("0.05"))
+	assert.True(t, errors.Is(err, ErrInvalidOrder))
+}
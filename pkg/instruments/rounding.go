@@ -0,0 +1,91 @@
+package instruments
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// ErrInvalidOrder is wrapped by the errors of Validate.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// TickSize returns the price increment of instrument at price. Options quote
+// in coarser ticks above the prices listed in tick_size_steps.
+func TickSize(instrument models.Instrument, price decimal.Decimal) decimal.Decimal {
+	tick := decimal.NewFromFloat(instrument.TickSize)
+	above := decimal.Zero
+	for _, step := range instrument.TickSizeSteps {
+		abovePrice := decimal.NewFromFloat(step.AbovePrice)
+		if price.Abs().GreaterThan(abovePrice) && abovePrice.GreaterThanOrEqual(above) {
+			tick = decimal.NewFromFloat(step.TickSize)
+			above = abovePrice
+		}
+	}
+	return tick
+}
+
+// RoundPrice rounds price to the nearest tick of instrument.
+func RoundPrice(instrument models.Instrument, price decimal.Decimal) decimal.Decimal {
+	return roundToStep(price, TickSize(instrument, price))
+}
+
+// AmountStep returns the increment order amounts of instrument must be a
+// multiple of: the contract size, or the minimum trade amount where that is
+// smaller, as for options traded in fractions of a contract.
+func AmountStep(instrument models.Instrument) decimal.Decimal {
+	step := decimal.NewFromFloat(instrument.ContractSize)
+	minimum := decimal.NewFromFloat(instrument.MinTradeAmount)
+	if step.IsZero() || (minimum.IsPositive() && minimum.LessThan(step)) {
+		step = minimum
+	}
+	return step
+}
+
+// RoundAmount rounds amount down to a multiple of AmountStep, so that an
+// order never grows by rounding.
+func RoundAmount(instrument models.Instrument, amount decimal.Decimal) decimal.Decimal {
+	step := AmountStep(instrument)
+	if !step.IsPositive() {
+		return amount
+	}
+	return amount.Div(step).Truncate(0).Mul(step)
+}
+
+// Validate checks that an order of amount at price is acceptable for
+// instrument once rounded. A zero price skips the price checks, for market
+// orders. Errors wrap ErrInvalidOrder.
+func Validate(instrument models.Instrument, price decimal.Decimal, amount decimal.Decimal) error {
+	name := instrument.InstrumentName
+	if !instrument.IsActive {
+		return fmt.Errorf("%w: %s is not active", ErrInvalidOrder, name)
+	}
+	if instrument.ExpirationTimestamp > 0 && time.UnixMilli(instrument.ExpirationTimestamp).Before(time.Now()) {
+		return fmt.Errorf("%w: %s has expired", ErrInvalidOrder, name)
+	}
+	if !price.IsZero() {
+		if instrument.Kind == "option" && price.IsNegative() {
+			return fmt.Errorf("%w: option price %s is negative", ErrInvalidOrder, price)
+		}
+		if rounded := RoundPrice(instrument, price); !rounded.Equal(price) {
+			return fmt.Errorf("%w: price %s is not a multiple of the %s tick size %s", ErrInvalidOrder, price, name, TickSize(instrument, price))
+		}
+	}
+	minimum := decimal.NewFromFloat(instrument.MinTradeAmount)
+	if !amount.IsPositive() || amount.LessThan(minimum) {
+		return fmt.Errorf("%w: amount %s is below the %s minimum of %s", ErrInvalidOrder, amount, name, minimum)
+	}
+	if rounded := RoundAmount(instrument, amount); !rounded.Equal(amount) {
+		return fmt.Errorf("%w: amount %s is not a multiple of the %s amount step %s", ErrInvalidOrder, amount, name, AmountStep(instrument))
+	}
+	return nil
+}
+
+func roundToStep(value decimal.Decimal, step decimal.Decimal) decimal.Decimal {
+	if !step.IsPositive() {
+		return value
+	}
+	return value.Div(step).Round(0).Mul(step)
+}
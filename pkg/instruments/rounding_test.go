@@ -0,0 +1,142 @@
+package instruments
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+var (
+	btcPerpetual = models.Instrument{
+		InstrumentName: "BTC-PERPETUAL",
+		Kind:           "future",
+		IsActive:       true,
+		TickSize:       0.5,
+		ContractSize:   10,
+		MinTradeAmount: 10,
+	}
+	ethPerpetual = models.Instrument{
+		InstrumentName: "ETH-PERPETUAL",
+		Kind:           "future",
+		IsActive:       true,
+		TickSize:       0.05,
+		ContractSize:   1,
+		MinTradeAmount: 1,
+	}
+	btcOption = models.Instrument{
+		InstrumentName: "BTC-27DEC30-100000-C",
+		Kind:           "option",
+		IsActive:       true,
+		TickSize:       0.0001,
+		TickSizeSteps:  []models.TickSizeStep{{AbovePrice: 0.005, TickSize: 0.0005}},
+		ContractSize:   1,
+		MinTradeAmount: 0.1,
+	}
+	btcUSDC = models.Instrument{
+		InstrumentName: "BTC_USDC-PERPETUAL",
+		Kind:           "future",
+		IsActive:       true,
+		TickSize:       1,
+		ContractSize:   0.001,
+		MinTradeAmount: 0.001,
+	}
+)
+
+func d(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestRoundToStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		price    decimal.Decimal
+		tickSize decimal.Decimal
+		expected decimal.Decimal
+	}{
+		{"exact", d("10"), d("0.5"), d("10")},
+		{"up", d("10.3"), d("0.5"), d("10.5")},
+		{"down", d("10.2"), d("0.5"), d("10")},
+		{"small tick", d("0.123"), d("0.01"), d("0.12")},
+		{"large tick", d("100"), d("10"), d("100")},
+		{"large round up", d("105"), d("10"), d("110")},
+		{"large round down", d("104"), d("10"), d("100")},
+		{"zero tick", d("10.3"), decimal.Zero, d("10.3")},
+	}
+	for _, tt := range tests {
+		assert.True(t, tt.expected.Equal(roundToStep(tt.price, tt.tickSize)), tt.name)
+	}
+}
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		instrument models.Instrument
+		price      string
+		want       string
+	}{
+		{btcPerpetual, "65000.3", "65000.5"},
+		{ethPerpetual, "3000.12", "3000.1"},
+		{btcOption, "0.00123", "0.0012"},
+		// Above 0.005 the option ticks in 0.0005.
+		{btcOption, "0.0123", "0.0125"},
+		{btcOption, "0.005", "0.005"},
+		{btcUSDC, "65000.4", "65000"},
+	}
+	for _, tt := range tests {
+		got := RoundPrice(tt.instrument, d(tt.price))
+		assert.True(t, d(tt.want).Equal(got), "%s %s: got %s", tt.instrument.InstrumentName, tt.price, got)
+	}
+}
+
+func TestRoundAmount(t *testing.T) {
+	tests := []struct {
+		instrument models.Instrument
+		amount     string
+		want       string
+	}{
+		{btcPerpetual, "105", "100"},
+		{ethPerpetual, "2.7", "2"},
+		{btcOption, "1.25", "1.2"},
+		{btcUSDC, "0.0127", "0.012"},
+	}
+	for _, tt := range tests {
+		got := RoundAmount(tt.instrument, d(tt.amount))
+		assert.True(t, d(tt.want).Equal(got), "%s %s: got %s", tt.instrument.InstrumentName, tt.amount, got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	expired := btcOption
+	expired.ExpirationTimestamp = time.Now().Add(-time.Hour).UnixMilli()
+	inactive := btcPerpetual
+	inactive.IsActive = false
+
+	tests := []struct {
+		name       string
+		instrument models.Instrument
+		price      string
+		amount     string
+		valid      bool
+	}{
+		{"valid", btcPerpetual, "65000.5", "100", true},
+		{"market", btcPerpetual, "0", "10", true},
+		{"off tick", btcPerpetual, "65000.3", "100", false},
+		{"below minimum", btcOption, "0.01", "0.05", false},
+		{"off amount step", btcPerpetual, "65000", "15", false},
+		{"zero amount", btcPerpetual, "65000", "0", false},
+		{"negative option price", btcOption, "-0.01", "1", false},
+		{"expired", expired, "0.01", "1", false},
+		{"inactive", inactive, "65000", "10", false},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.instrument, d(tt.price), d(tt.amount))
+		if tt.valid {
+			assert.NoError(t, err, tt.name)
+		} else {
+			assert.True(t, errors.Is(err, ErrInvalidOrder), "%s: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+type BlockTradeConfirmationNotification struct {
+	Nonce             string          `json:"nonce"`
+	AppName           string          `json:"app_name"`
+	Timestamp         int64           `json:"timestamp"`
+	UserID            int64           `json:"user_id"`
+	Role              string          `json:"role"`
+	State             BlockTradeState `json:"state"`
+	CounterpartyState BlockTradeState `json:"counterparty_state"`
+	Trades            []BlockTradeLeg `json:"trades"`
+}
+
+type BlockTradeState struct {
+	Value     string `json:"value"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+type BlockTradeLeg struct {
+	InstrumentName string  `json:"instrument_name"`
+	Direction      string  `json:"direction"`
+	Price          float64 `json:"price"`
+	Amount         float64 `json:"amount"`
+}
@@ -0,0 +1,112 @@
+package decimalmodels
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type AccountSummary struct {
+	AvailableFunds            decimal.Decimal `json:"available_funds"`
+	AvailableWithdrawalFunds  decimal.Decimal `json:"available_withdrawal_funds"`
+	Balance                   decimal.Decimal `json:"balance"`
+	Currency                  string          `json:"currency"`
+	DeltaTotal                decimal.Decimal `json:"delta_total"`
+	DepositAddress            string          `json:"deposit_address"`
+	Email                     string          `json:"email"`
+	Equity                    decimal.Decimal `json:"equity"`
+	FuturesPl                 decimal.Decimal `json:"futures_pl"`
+	FuturesSessionRpl         decimal.Decimal `json:"futures_session_rpl"`
+	FuturesSessionUpl         decimal.Decimal `json:"futures_session_upl"`
+	ID                        int             `json:"id"`
+	InitialMargin             decimal.Decimal `json:"initial_margin"`
+	MaintenanceMargin         decimal.Decimal `json:"maintenance_margin"`
+	MarginBalance             decimal.Decimal `json:"margin_balance"`
+	OptionsDelta              decimal.Decimal `json:"options_delta"`
+	OptionsGamma              decimal.Decimal `json:"options_gamma"`
+	OptionsPl                 decimal.Decimal `json:"options_pl"`
+	OptionsSessionRpl         decimal.Decimal `json:"options_session_rpl"`
+	OptionsSessionUpl         decimal.Decimal `json:"options_session_upl"`
+	OptionsTheta              decimal.Decimal `json:"options_theta"`
+	OptionsVega               decimal.Decimal `json:"options_vega"`
+	PortfolioMarginingEnabled bool            `json:"portfolio_margining_enabled"`
+	SessionFunding            decimal.Decimal `json:"session_funding"`
+	SessionRpl                decimal.Decimal `json:"session_rpl"`
+	SessionUpl                decimal.Decimal `json:"session_upl"`
+	SystemName                string          `json:"system_name"`
+	TfaEnabled                bool            `json:"tfa_enabled"`
+	TotalPl                   decimal.Decimal `json:"total_pl"`
+	Type                      string          `json:"type"`
+	Username                  string          `json:"username"`
+}
+
+func FromAccountSummary(s models.AccountSummary) AccountSummary {
+	return AccountSummary{
+		AvailableFunds:            fromFloat(s.AvailableFunds),
+		AvailableWithdrawalFunds:  fromFloat(s.AvailableWithdrawalFunds),
+		Balance:                   fromFloat(s.Balance),
+		Currency:                  s.Currency,
+		DeltaTotal:                fromFloat(s.DeltaTotal),
+		DepositAddress:            s.DepositAddress,
+		Email:                     s.Email,
+		Equity:                    fromFloat(s.Equity),
+		FuturesPl:                 fromFloat(s.FuturesPl),
+		FuturesSessionRpl:         fromFloat(s.FuturesSessionRpl),
+		FuturesSessionUpl:         fromFloat(s.FuturesSessionUpl),
+		ID:                        s.ID,
+		InitialMargin:             fromFloat(s.InitialMargin),
+		MaintenanceMargin:         fromFloat(s.MaintenanceMargin),
+		MarginBalance:             fromFloat(s.MarginBalance),
+		OptionsDelta:              fromFloat(s.OptionsDelta),
+		OptionsGamma:              fromFloat(s.OptionsGamma),
+		OptionsPl:                 fromFloat(s.OptionsPl),
+		OptionsSessionRpl:         fromFloat(s.OptionsSessionRpl),
+		OptionsSessionUpl:         fromFloat(s.OptionsSessionUpl),
+		OptionsTheta:              fromFloat(s.OptionsTheta),
+		OptionsVega:               fromFloat(s.OptionsVega),
+		PortfolioMarginingEnabled: s.PortfolioMarginingEnabled,
+		SessionFunding:            fromFloat(s.SessionFunding),
+		SessionRpl:                fromFloat(s.SessionRpl),
+		SessionUpl:                fromFloat(s.SessionUpl),
+		SystemName:                s.SystemName,
+		TfaEnabled:                s.TfaEnabled,
+		TotalPl:                   fromFloat(s.TotalPl),
+		Type:                      s.Type,
+		Username:                  s.Username,
+	}
+}
+
+func (s AccountSummary) Float() models.AccountSummary {
+	return models.AccountSummary{
+		AvailableFunds:            toFloat(s.AvailableFunds),
+		AvailableWithdrawalFunds:  toFloat(s.AvailableWithdrawalFunds),
+		Balance:                   toFloat(s.Balance),
+		Currency:                  s.Currency,
+		DeltaTotal:                toFloat(s.DeltaTotal),
+		DepositAddress:            s.DepositAddress,
+		Email:                     s.Email,
+		Equity:                    toFloat(s.Equity),
+		FuturesPl:                 toFloat(s.FuturesPl),
+		FuturesSessionRpl:         toFloat(s.FuturesSessionRpl),
+		FuturesSessionUpl:         toFloat(s.FuturesSessionUpl),
+		ID:                        s.ID,
+		InitialMargin:             toFloat(s.InitialMargin),
+		MaintenanceMargin:         toFloat(s.MaintenanceMargin),
+		MarginBalance:             toFloat(s.MarginBalance),
+		OptionsDelta:              toFloat(s.OptionsDelta),
+		OptionsGamma:              toFloat(s.OptionsGamma),
+		OptionsPl:                 toFloat(s.OptionsPl),
+		OptionsSessionRpl:         toFloat(s.OptionsSessionRpl),
+		OptionsSessionUpl:         toFloat(s.OptionsSessionUpl),
+		OptionsTheta:              toFloat(s.OptionsTheta),
+		OptionsVega:               toFloat(s.OptionsVega),
+		PortfolioMarginingEnabled: s.PortfolioMarginingEnabled,
+		SessionFunding:            toFloat(s.SessionFunding),
+		SessionRpl:                toFloat(s.SessionRpl),
+		SessionUpl:                toFloat(s.SessionUpl),
+		SystemName:                s.SystemName,
+		TfaEnabled:                s.TfaEnabled,
+		TotalPl:                   toFloat(s.TotalPl),
+		Type:                      s.Type,
+		Username:                  s.Username,
+	}
+}
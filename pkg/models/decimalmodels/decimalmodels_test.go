@@ -0,0 +1,137 @@
+package decimalmodels
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestUnmarshal_Lossless(t *testing.T) {
+	data := `{
+		"trades": [{
+			"trade_id": "1",
+			"price": 0.1234567890123456789,
+			"amount": 12345678901234.56789,
+			"fee": 0.000000015,
+			"fee_currency": "BTC",
+			"index_price": null
+		}],
+		"order": {
+			"order_id": "ETH-1",
+			"price": 3000.05,
+			"amount": 0.3,
+			"filled_amount": "0.3",
+			"average_price": 3000.05,
+			"commission": 0.0000001
+		}
+	}`
+
+	var resp OrderResponse
+	require.NoError(t, json.Unmarshal([]byte(data), &resp))
+
+	trade := resp.Trades[0]
+	assert.Equal(t, "0.1234567890123456789", trade.Price.String())
+	assert.Equal(t, "12345678901234.56789", trade.Amount.String())
+	assert.Equal(t, "0.000000015", trade.Fee.String())
+	assert.True(t, trade.IndexPrice.IsZero())
+
+	assert.Equal(t, "3000.05", resp.Order.Price.String())
+	assert.Equal(t, "0.3", resp.Order.FilledAmount.String())
+	assert.Equal(t, "0.0000001", resp.Order.Commission.String())
+	assert.True(t, resp.Order.Amount.Sub(resp.Order.FilledAmount).IsZero())
+}
+
+func TestPrice_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"number", `60000.5`, "60000.5", false},
+		{"string", `"60000.5"`, "60000.5", false},
+		{"market price", `"market_price"`, "0", false},
+		{"null", `null`, "0", false},
+		{"invalid", `"abc"`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Price
+			err := json.Unmarshal([]byte(tt.data), &p)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p.String())
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	trade := models.Trade{TradeID: "1", Price: 0.1, Amount: 10, IndexPrice: 60000.25, Iv: 55.5}
+	assert.Equal(t, "0.1", FromTrade(trade).Price.String())
+	assert.Equal(t, trade, FromTrade(trade).Float())
+
+	userTrade := models.UserTrade{TradeID: "2", Price: 0.2, Fee: 0.00001, FeeCurrency: "BTC", Amount: 1}
+	assert.Equal(t, "0.00001", FromUserTrade(userTrade).Fee.String())
+	assert.Equal(t, userTrade, FromUserTrade(userTrade).Float())
+
+	trades := models.GetUserTradesResponse{Trades: []models.UserTrade{userTrade}, HasMore: true}
+	assert.Equal(t, trades, FromGetUserTradesResponse(trades).Float())
+
+	position := models.Position{InstrumentName: "BTC-PERPETUAL", Size: -100, AveragePrice: 60000.5, FloatingProfitLoss: 0.0003}
+	assert.Equal(t, position, FromPosition(position).Float())
+
+	summary := models.AccountSummary{Currency: "BTC", Balance: 1.2345, Equity: 1.3, ID: 7}
+	assert.Equal(t, "1.2345", FromAccountSummary(summary).Balance.String())
+	assert.Equal(t, summary, FromAccountSummary(summary).Float())
+
+	order := models2.Order{OrderID: "1", Price: 60000.5, Amount: 10, FilledAmount: 5, Commission: 0.0001, Usd: 10.5}
+	assert.Equal(t, order, FromOrder(order).Float())
+
+	isRebalance := false
+	restOrder := restmodels.Order{
+		OrderID:      "2",
+		Price:        0.3,
+		Amount:       1,
+		Status:       restmodels.Open,
+		OrderType:    restmodels.StopLimit,
+		Trigger:      restmodels.MarkPrice,
+		TriggerPrice: 0.25,
+		Direction:    restmodels.Buy,
+		IsRebalance:  &isRebalance,
+	}
+	assert.Equal(t, restOrder, FromRestOrder(restOrder).RestOrder())
+
+	buy := models.BuyResponse{Trades: []models.Trade{trade}, Order: order}
+	assert.Equal(t, buy, FromBuyResponse(buy).BuyResponse())
+	sell := models.SellResponse(buy)
+	assert.Equal(t, sell, FromSellResponse(sell).SellResponse())
+	result := restmodels.OrderResult{Trades: []models.Trade{{TradeID: "3", Price: 2.5, Amount: 1}}, Order: restOrder}
+	assert.Equal(t, result, FromOrderResult(result).OrderResult())
+}
+
+func TestConversions_Sum(t *testing.T) {
+	// Summing the float fees drifts; the decimal sum does not.
+	var trades []models.UserTrade
+	for i := 0; i < 10; i++ {
+		trades = append(trades, models.UserTrade{Fee: 0.1})
+	}
+
+	floatSum := 0.0
+	decimalSum := decimal.Zero
+	for _, trade := range trades {
+		floatSum += trade.Fee
+		decimalSum = decimalSum.Add(FromUserTrade(trade).Fee)
+	}
+	assert.NotEqual(t, 1.0, floatSum)
+	assert.True(t, decimalSum.Equal(decimal.NewFromInt(1)))
+}
@@ -0,0 +1,25 @@
+// Package decimalmodels holds variants of the account and trading models
+// whose prices, amounts, fees and balances are decimal.Decimal instead of
+// float64. They use the same JSON tags as pkg/models, so a response is
+// parsed losslessly by decoding it straight into one of these types:
+//
+//	var resp decimalmodels.OrderResponse
+//	err := ws.CallContext(ctx, "private/buy", params, &resp)
+//
+//	trades, err := rest.Call[decimalmodels.GetUserTradesResponse](ctx, client, "private/get_user_trades_by_instrument", params)
+//
+// Every type converts to and from its float counterpart, so the decimal
+// mode can be adopted one call site at a time.
+package decimalmodels
+
+import "github.com/shopspring/decimal"
+
+// fromFloat converts f by its shortest decimal representation, so 0.1
+// becomes exactly 0.1.
+func fromFloat(f float64) decimal.Decimal {
+	return decimal.NewFromFloat(f)
+}
+
+func toFloat(d decimal.Decimal) float64 {
+	return d.InexactFloat64()
+}
@@ -0,0 +1,164 @@
+package decimalmodels
+
+import (
+	"github.com/shopspring/decimal"
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+	models2 "github.com/xingxing/deribit-api/clients/websocket/models"
+)
+
+// Order holds the fields of both the WebSocket and the REST order models.
+type Order struct {
+	Advanced            string          `json:"advanced,omitempty"`
+	Amount              decimal.Decimal `json:"amount"`
+	API                 bool            `json:"api"`
+	Web                 bool            `json:"web"`
+	TimeInForce         string          `json:"time_in_force"`
+	Replaced            bool            `json:"replaced"`
+	ReduceOnly          bool            `json:"reduce_only"`
+	ProfitLoss          decimal.Decimal `json:"profit_loss"`
+	Price               Price           `json:"price"`
+	PostOnly            bool            `json:"post_only"`
+	StopPrice           decimal.Decimal `json:"stop_price"`
+	Trigger             string          `json:"trigger,omitempty"`
+	TriggerPrice        decimal.Decimal `json:"trigger_price"`
+	TriggerOffset       decimal.Decimal `json:"trigger_offset"`
+	Triggered           bool            `json:"triggered,omitempty"`
+	OrderType           string          `json:"order_type"`
+	OrderState          string          `json:"order_state"`
+	OrderID             string          `json:"order_id"`
+	MaxShow             decimal.Decimal `json:"max_show"`
+	LastUpdateTimestamp int64           `json:"last_update_timestamp"`
+	Label               string          `json:"label"`
+	IsRebalance         *bool           `json:"is_rebalance"`
+	IsLiquidation       bool            `json:"is_liquidation"`
+	InstrumentName      string          `json:"instrument_name"`
+	FilledAmount        decimal.Decimal `json:"filled_amount"`
+	Direction           string          `json:"direction"`
+	CreationTimestamp   int64           `json:"creation_timestamp"`
+	Commission          decimal.Decimal `json:"commission"`
+	AveragePrice        decimal.Decimal `json:"average_price"`
+	Implv               decimal.Decimal `json:"implv"`
+	Usd                 decimal.Decimal `json:"usd"`
+}
+
+func FromOrder(o models2.Order) Order {
+	return Order{
+		Advanced:            o.Advanced,
+		Amount:              fromFloat(o.Amount),
+		API:                 o.API,
+		TimeInForce:         o.TimeInForce,
+		ReduceOnly:          o.ReduceOnly,
+		ProfitLoss:          fromFloat(o.ProfitLoss),
+		Price:               Price{fromFloat(o.Price.ToFloat64())},
+		PostOnly:            o.PostOnly,
+		StopPrice:           fromFloat(o.StopPrice),
+		Triggered:           o.Triggered,
+		OrderType:           o.OrderType,
+		OrderState:          o.OrderState,
+		OrderID:             o.OrderID,
+		MaxShow:             fromFloat(o.MaxShow),
+		LastUpdateTimestamp: o.LastUpdateTimestamp,
+		Label:               o.Label,
+		IsLiquidation:       o.IsLiquidation,
+		InstrumentName:      o.InstrumentName,
+		FilledAmount:        fromFloat(o.FilledAmount),
+		Direction:           o.Direction,
+		CreationTimestamp:   o.CreationTimestamp,
+		Commission:          fromFloat(o.Commission),
+		AveragePrice:        fromFloat(o.AveragePrice),
+		Implv:               fromFloat(o.Implv),
+		Usd:                 fromFloat(o.Usd),
+	}
+}
+
+// Float converts o to the WebSocket order model.
+func (o Order) Float() models2.Order {
+	return models2.Order{
+		Advanced:            o.Advanced,
+		Amount:              toFloat(o.Amount),
+		API:                 o.API,
+		TimeInForce:         o.TimeInForce,
+		ReduceOnly:          o.ReduceOnly,
+		ProfitLoss:          toFloat(o.ProfitLoss),
+		Price:               models2.Price(toFloat(o.Price.Decimal)),
+		PostOnly:            o.PostOnly,
+		StopPrice:           toFloat(o.StopPrice),
+		Triggered:           o.Triggered,
+		OrderType:           o.OrderType,
+		OrderState:          o.OrderState,
+		OrderID:             o.OrderID,
+		MaxShow:             toFloat(o.MaxShow),
+		LastUpdateTimestamp: o.LastUpdateTimestamp,
+		Label:               o.Label,
+		IsLiquidation:       o.IsLiquidation,
+		InstrumentName:      o.InstrumentName,
+		FilledAmount:        toFloat(o.FilledAmount),
+		Direction:           o.Direction,
+		CreationTimestamp:   o.CreationTimestamp,
+		Commission:          toFloat(o.Commission),
+		AveragePrice:        toFloat(o.AveragePrice),
+		Implv:               toFloat(o.Implv),
+		Usd:                 toFloat(o.Usd),
+	}
+}
+
+func FromRestOrder(o restmodels.Order) Order {
+	return Order{
+		Advanced:            string(o.Advanced),
+		Amount:              fromFloat(o.Amount),
+		API:                 o.API,
+		Web:                 o.Web,
+		TimeInForce:         o.TimeInForce,
+		Replaced:            o.Replaced,
+		ReduceOnly:          o.ReduceOnly,
+		Price:               Price{fromFloat(o.Price)},
+		PostOnly:            o.PostOnly,
+		Trigger:             string(o.Trigger),
+		TriggerPrice:        fromFloat(o.TriggerPrice),
+		TriggerOffset:       fromFloat(o.TriggerOffset),
+		OrderType:           string(o.OrderType),
+		OrderState:          string(o.Status),
+		OrderID:             o.OrderID,
+		MaxShow:             fromFloat(o.MaxShow),
+		LastUpdateTimestamp: o.LastUpdate,
+		Label:               o.Label,
+		IsRebalance:         o.IsRebalance,
+		IsLiquidation:       o.IsLiquidation,
+		InstrumentName:      o.InstrumentName,
+		FilledAmount:        fromFloat(o.FilledAmount),
+		Direction:           string(o.Direction),
+		CreationTimestamp:   o.Timestamp,
+		AveragePrice:        fromFloat(o.AveragePrice),
+	}
+}
+
+// RestOrder converts o to the REST order model.
+func (o Order) RestOrder() restmodels.Order {
+	return restmodels.Order{
+		Web:            o.Web,
+		TimeInForce:    o.TimeInForce,
+		Replaced:       o.Replaced,
+		ReduceOnly:     o.ReduceOnly,
+		Price:          toFloat(o.Price.Decimal),
+		PostOnly:       o.PostOnly,
+		OrderType:      restmodels.OrderType(o.OrderType),
+		Status:         restmodels.OrderStatus(o.OrderState),
+		OrderID:        o.OrderID,
+		MaxShow:        toFloat(o.MaxShow),
+		LastUpdate:     o.LastUpdateTimestamp,
+		Label:          o.Label,
+		IsRebalance:    o.IsRebalance,
+		IsLiquidation:  o.IsLiquidation,
+		InstrumentName: o.InstrumentName,
+		FilledAmount:   toFloat(o.FilledAmount),
+		Direction:      restmodels.Direction(o.Direction),
+		Timestamp:      o.CreationTimestamp,
+		AveragePrice:   toFloat(o.AveragePrice),
+		API:            o.API,
+		Amount:         toFloat(o.Amount),
+		Trigger:        restmodels.Trigger(o.Trigger),
+		TriggerPrice:   toFloat(o.TriggerPrice),
+		TriggerOffset:  toFloat(o.TriggerOffset),
+		Advanced:       restmodels.Advanced(o.Advanced),
+	}
+}
@@ -0,0 +1,81 @@
+package decimalmodels
+
+import (
+	restmodels "github.com/xingxing/deribit-api/clients/rest/models"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// OrderResponse is the result of private/buy, private/sell and
+// private/edit. Its trades keep the fee fields that the float models drop.
+type OrderResponse struct {
+	Trades []UserTrade `json:"trades"`
+	Order  Order       `json:"order"`
+}
+
+func FromBuyResponse(r models.BuyResponse) OrderResponse {
+	return OrderResponse{Trades: fromTrades(r.Trades), Order: FromOrder(r.Order)}
+}
+
+func FromSellResponse(r models.SellResponse) OrderResponse {
+	return FromBuyResponse(models.BuyResponse(r))
+}
+
+func FromOrderResult(r restmodels.OrderResult) OrderResponse {
+	return OrderResponse{Trades: fromTrades(r.Trades), Order: FromRestOrder(r.Order)}
+}
+
+func (r OrderResponse) BuyResponse() models.BuyResponse {
+	return models.BuyResponse{Trades: r.trades(), Order: r.Order.Float()}
+}
+
+func (r OrderResponse) SellResponse() models.SellResponse {
+	return models.SellResponse(r.BuyResponse())
+}
+
+func (r OrderResponse) OrderResult() restmodels.OrderResult {
+	return restmodels.OrderResult{Trades: r.trades(), Order: r.Order.RestOrder()}
+}
+
+func fromTrades(trades []models.Trade) []UserTrade {
+	if trades == nil {
+		return nil
+	}
+	result := make([]UserTrade, len(trades))
+	for i, t := range trades {
+		result[i] = UserTrade{
+			TradeSeq:       t.TradeSeq,
+			TradeID:        t.TradeID,
+			Timestamp:      t.Timestamp,
+			TickDirection:  t.TickDirection,
+			Price:          fromFloat(t.Price),
+			Iv:             fromFloat(t.Iv),
+			InstrumentName: t.InstrumentName,
+			IndexPrice:     fromFloat(t.IndexPrice),
+			Direction:      t.Direction,
+			Amount:         fromFloat(t.Amount),
+		}
+	}
+	return result
+}
+
+func (r OrderResponse) trades() []models.Trade {
+	if r.Trades == nil {
+		return nil
+	}
+	result := make([]models.Trade, len(r.Trades))
+	for i, t := range r.Trades {
+		result[i] = models.Trade{
+			TradeSeq:       t.TradeSeq,
+			TradeID:        t.TradeID,
+			Timestamp:      t.Timestamp,
+			TickDirection:  t.TickDirection,
+			Price:          toFloat(t.Price),
+			Iv:             toFloat(t.Iv),
+			InstrumentName: t.InstrumentName,
+			IndexPrice:     toFloat(t.IndexPrice),
+			Direction:      t.Direction,
+			Amount:         toFloat(t.Amount),
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package decimalmodels
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type Position struct {
+	AveragePrice              decimal.Decimal `json:"average_price"`
+	Delta                     decimal.Decimal `json:"delta"`
+	Direction                 string          `json:"direction"`
+	EstimatedLiquidationPrice decimal.Decimal `json:"estimated_liquidation_price"`
+	FloatingProfitLoss        decimal.Decimal `json:"floating_profit_loss"`
+	IndexPrice                decimal.Decimal `json:"index_price"`
+	InitialMargin             decimal.Decimal `json:"initial_margin"`
+	InstrumentName            string          `json:"instrument_name"`
+	Kind                      string          `json:"kind"`
+	MaintenanceMargin         decimal.Decimal `json:"maintenance_margin"`
+	MarkPrice                 decimal.Decimal `json:"mark_price"`
+	OpenOrdersMargin          decimal.Decimal `json:"open_orders_margin"`
+	RealizedProfitLoss        decimal.Decimal `json:"realized_profit_loss"`
+	SettlementPrice           decimal.Decimal `json:"settlement_price"`
+	Size                      decimal.Decimal `json:"size"`
+	SizeCurrency              decimal.Decimal `json:"size_currency"`
+	TotalProfitLoss           decimal.Decimal `json:"total_profit_loss"`
+}
+
+func FromPosition(p models.Position) Position {
+	return Position{
+		AveragePrice:              fromFloat(p.AveragePrice),
+		Delta:                     fromFloat(p.Delta),
+		Direction:                 p.Direction,
+		EstimatedLiquidationPrice: fromFloat(p.EstimatedLiquidationPrice),
+		FloatingProfitLoss:        fromFloat(p.FloatingProfitLoss),
+		IndexPrice:                fromFloat(p.IndexPrice),
+		InitialMargin:             fromFloat(p.InitialMargin),
+		InstrumentName:            p.InstrumentName,
+		Kind:                      p.Kind,
+		MaintenanceMargin:         fromFloat(p.MaintenanceMargin),
+		MarkPrice:                 fromFloat(p.MarkPrice),
+		OpenOrdersMargin:          fromFloat(p.OpenOrdersMargin),
+		RealizedProfitLoss:        fromFloat(p.RealizedProfitLoss),
+		SettlementPrice:           fromFloat(p.SettlementPrice),
+		Size:                      fromFloat(p.Size),
+		SizeCurrency:              fromFloat(p.SizeCurrency),
+		TotalProfitLoss:           fromFloat(p.TotalProfitLoss),
+	}
+}
+
+func (p Position) Float() models.Position {
+	return models.Position{
+		AveragePrice:              toFloat(p.AveragePrice),
+		Delta:                     toFloat(p.Delta),
+		Direction:                 p.Direction,
+		EstimatedLiquidationPrice: toFloat(p.EstimatedLiquidationPrice),
+		FloatingProfitLoss:        toFloat(p.FloatingProfitLoss),
+		IndexPrice:                toFloat(p.IndexPrice),
+		InitialMargin:             toFloat(p.InitialMargin),
+		InstrumentName:            p.InstrumentName,
+		Kind:                      p.Kind,
+		MaintenanceMargin:         toFloat(p.MaintenanceMargin),
+		MarkPrice:                 toFloat(p.MarkPrice),
+		OpenOrdersMargin:          toFloat(p.OpenOrdersMargin),
+		RealizedProfitLoss:        toFloat(p.RealizedProfitLoss),
+		SettlementPrice:           toFloat(p.SettlementPrice),
+		Size:                      toFloat(p.Size),
+		SizeCurrency:              toFloat(p.SizeCurrency),
+		TotalProfitLoss:           toFloat(p.TotalProfitLoss),
+	}
+}
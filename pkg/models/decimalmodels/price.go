@@ -0,0 +1,17 @@
+package decimalmodels
+
+import "github.com/shopspring/decimal"
+
+// Price is an order price. Deribit reports market orders with the price
+// "market_price", which decodes as zero.
+type Price struct {
+	decimal.Decimal
+}
+
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if string(data) == `"market_price"` {
+		p.Decimal = decimal.Zero
+		return nil
+	}
+	return p.Decimal.UnmarshalJSON(data)
+}
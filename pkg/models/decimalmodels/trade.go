@@ -0,0 +1,49 @@
+package decimalmodels
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type Trade struct {
+	TradeSeq       int             `json:"trade_seq"`
+	TradeID        string          `json:"trade_id"`
+	Timestamp      int64           `json:"timestamp"`
+	TickDirection  int             `json:"tick_direction"`
+	Price          decimal.Decimal `json:"price"`
+	Iv             decimal.Decimal `json:"iv"`
+	InstrumentName string          `json:"instrument_name"`
+	IndexPrice     decimal.Decimal `json:"index_price"`
+	Direction      string          `json:"direction"`
+	Amount         decimal.Decimal `json:"amount"`
+}
+
+func FromTrade(t models.Trade) Trade {
+	return Trade{
+		TradeSeq:       t.TradeSeq,
+		TradeID:        t.TradeID,
+		Timestamp:      t.Timestamp,
+		TickDirection:  t.TickDirection,
+		Price:          fromFloat(t.Price),
+		Iv:             fromFloat(t.Iv),
+		InstrumentName: t.InstrumentName,
+		IndexPrice:     fromFloat(t.IndexPrice),
+		Direction:      t.Direction,
+		Amount:         fromFloat(t.Amount),
+	}
+}
+
+func (t Trade) Float() models.Trade {
+	return models.Trade{
+		TradeSeq:       t.TradeSeq,
+		TradeID:        t.TradeID,
+		Timestamp:      t.Timestamp,
+		TickDirection:  t.TickDirection,
+		Price:          toFloat(t.Price),
+		Iv:             toFloat(t.Iv),
+		InstrumentName: t.InstrumentName,
+		IndexPrice:     toFloat(t.IndexPrice),
+		Direction:      t.Direction,
+		Amount:         toFloat(t.Amount),
+	}
+}
@@ -0,0 +1,98 @@
+package decimalmodels
+
+import (
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type UserTrade struct {
+	TradeSeq       int             `json:"trade_seq"`
+	TradeID        string          `json:"trade_id"`
+	Timestamp      int64           `json:"timestamp"`
+	TickDirection  int             `json:"tick_direction"`
+	State          string          `json:"state"`
+	SelfTrade      bool            `json:"self_trade"`
+	Price          decimal.Decimal `json:"price"`
+	Iv             decimal.Decimal `json:"iv"`
+	OrderType      string          `json:"order_type"`
+	OrderID        string          `json:"order_id"`
+	MatchingID     interface{}     `json:"matching_id"`
+	Liquidity      string          `json:"liquidity"`
+	InstrumentName string          `json:"instrument_name"`
+	IndexPrice     decimal.Decimal `json:"index_price"`
+	FeeCurrency    string          `json:"fee_currency"`
+	Fee            decimal.Decimal `json:"fee"`
+	Direction      string          `json:"direction"`
+	Amount         decimal.Decimal `json:"amount"`
+}
+
+func FromUserTrade(t models.UserTrade) UserTrade {
+	return UserTrade{
+		TradeSeq:       t.TradeSeq,
+		TradeID:        t.TradeID,
+		Timestamp:      t.Timestamp,
+		TickDirection:  t.TickDirection,
+		State:          t.State,
+		SelfTrade:      t.SelfTrade,
+		Price:          fromFloat(t.Price),
+		OrderType:      t.OrderType,
+		OrderID:        t.OrderID,
+		MatchingID:     t.MatchingID,
+		Liquidity:      t.Liquidity,
+		InstrumentName: t.InstrumentName,
+		IndexPrice:     fromFloat(t.IndexPrice),
+		FeeCurrency:    t.FeeCurrency,
+		Fee:            fromFloat(t.Fee),
+		Direction:      t.Direction,
+		Amount:         fromFloat(t.Amount),
+	}
+}
+
+func (t UserTrade) Float() models.UserTrade {
+	return models.UserTrade{
+		TradeSeq:       t.TradeSeq,
+		TradeID:        t.TradeID,
+		Timestamp:      t.Timestamp,
+		TickDirection:  t.TickDirection,
+		State:          t.State,
+		SelfTrade:      t.SelfTrade,
+		Price:          toFloat(t.Price),
+		OrderType:      t.OrderType,
+		OrderID:        t.OrderID,
+		MatchingID:     t.MatchingID,
+		Liquidity:      t.Liquidity,
+		InstrumentName: t.InstrumentName,
+		IndexPrice:     toFloat(t.IndexPrice),
+		FeeCurrency:    t.FeeCurrency,
+		Fee:            toFloat(t.Fee),
+		Direction:      t.Direction,
+		Amount:         toFloat(t.Amount),
+	}
+}
+
+type GetUserTradesResponse struct {
+	Trades  []UserTrade `json:"trades"`
+	HasMore bool        `json:"has_more"`
+}
+
+func FromGetUserTradesResponse(r models.GetUserTradesResponse) GetUserTradesResponse {
+	result := GetUserTradesResponse{HasMore: r.HasMore}
+	if r.Trades != nil {
+		result.Trades = make([]UserTrade, len(r.Trades))
+		for i, t := range r.Trades {
+			result.Trades[i] = FromUserTrade(t)
+		}
+	}
+	return result
+}
+
+func (r GetUserTradesResponse) Float() models.GetUserTradesResponse {
+	result := models.GetUserTradesResponse{HasMore: r.HasMore}
+	if r.Trades != nil {
+		result.Trades = make([]models.UserTrade, len(r.Trades))
+		for i, t := range r.Trades {
+			result.Trades[i] = t.Float()
+		}
+	}
+	return result
+}
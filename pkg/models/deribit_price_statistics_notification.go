@@ -0,0 +1,9 @@
+package models
+
+type DeribitPriceStatisticsNotification struct {
+	Low24H         float64 `json:"low24h"`
+	High24H        float64 `json:"high24h"`
+	Change24H      float64 `json:"change24h"`
+	HighVolatility bool    `json:"high_volatility"`
+	IndexName      string  `json:"index_name"`
+}
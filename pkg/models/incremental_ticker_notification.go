@@ -0,0 +1,8 @@
+package models
+
+// IncrementalTickerNotification is a full ticker for Type "snapshot" and
+// only the changed fields for Type "change".
+type IncrementalTickerNotification struct {
+	Type string `json:"type"`
+	TickerNotification
+}
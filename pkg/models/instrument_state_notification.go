@@ -0,0 +1,7 @@
+package models
+
+type InstrumentStateNotification struct {
+	Timestamp      int64  `json:"timestamp"`
+	State          string `json:"state"`
+	InstrumentName string `json:"instrument_name"`
+}
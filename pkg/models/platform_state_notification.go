@@ -0,0 +1,8 @@
+package models
+
+type PlatformStateNotification struct {
+	PriceIndex                         string `json:"price_index"`
+	Locked                             bool   `json:"locked"`
+	Maintenance                        bool   `json:"maintenance"`
+	AllowUnauthenticatedPublicRequests bool   `json:"allow_unauthenticated_public_requests"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type TickSizeStep struct {
+	AbovePrice float64 `json:"above_price"`
+	TickSize   float64 `json:"tick_size"`
+}
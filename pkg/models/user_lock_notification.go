@@ -0,0 +1,6 @@
+package models
+
+type UserLockNotification struct {
+	Locked   bool   `json:"locked"`
+	Currency string `json:"currency"`
+}
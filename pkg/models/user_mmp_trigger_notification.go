@@ -0,0 +1,7 @@
+package models
+
+type UserMMPTriggerNotification struct {
+	IndexName   string `json:"index_name"`
+	FrozenUntil int64  `json:"frozen_until"`
+	MMPGroup    string `json:"mmp_group"`
+}
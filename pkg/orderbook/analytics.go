@@ -0,0 +1,196 @@
+package orderbook
+
+import (
+	"errors"
+	"math"
+
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/instruments"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// ErrInsufficientDepth is returned when a side cannot fill the requested
+// size.
+var ErrInsufficientDepth = errors.New("insufficient depth")
+
+// ErrEmptyBook is returned when a side has no levels.
+var ErrEmptyBook = errors.New("empty book")
+
+// ErrInvalidSize is returned for a size that is not positive.
+var ErrInvalidSize = errors.New("size must be positive")
+
+// ErrInvalidPercent is returned for a percentage that is not positive.
+var ErrInvalidPercent = errors.New("percent must be positive")
+
+// Side selects the side of a book an order fills against: a buy order
+// takes the asks, a sell order the bids.
+type Side int
+
+const (
+	Bids Side = iota
+	Asks
+)
+
+// Depth is a consistent copy of both sides of a book, best levels first.
+// Book.Depth takes one from a live book and restmodels.OrderBook.Depth from
+// a REST snapshot; the analytics below work on either.
+type Depth struct {
+	Bids []Level
+	Asks []Level
+}
+
+// Depth returns up to n levels of each side. n <= 0 returns the whole book.
+func (b *Book) Depth(n int) Depth {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return Depth{Bids: top(b.bids, n), Asks: top(b.asks, n)}
+}
+
+func (d Depth) side(side Side) []Level {
+	if side == Bids {
+		return d.Bids
+	}
+	return d.Asks
+}
+
+// Mid returns the midpoint of the best bid and ask.
+func (d Depth) Mid() (float64, bool) {
+	if len(d.Bids) == 0 || len(d.Asks) == 0 {
+		return 0, false
+	}
+	return (d.Bids[0].Price + d.Asks[0].Price) / 2, true
+}
+
+// AverageFillPrice walks side until size is filled and returns the
+// average price of what it filled. filled is less than size when the side
+// is too thin.
+func (d Depth) AverageFillPrice(side Side, size float64) (price, filled float64) {
+	var notional float64
+	for _, level := range d.side(side) {
+		if filled >= size {
+			break
+		}
+		amount := math.Min(level.Amount, size-filled)
+		notional += amount * level.Price
+		filled += amount
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return notional / filled, filled
+}
+
+// VWAP returns the volume weighted average price of filling size against
+// side. It fails with ErrInvalidSize unless size is positive and with
+// ErrInsufficientDepth if the side holds less than size.
+func (d Depth) VWAP(side Side, size float64) (float64, error) {
+	if !(size > 0) {
+		return 0, ErrInvalidSize
+	}
+	if len(d.side(side)) == 0 {
+		return 0, ErrEmptyBook
+	}
+	price, filled := d.AverageFillPrice(side, size)
+	if filled < size {
+		return 0, ErrInsufficientDepth
+	}
+	return price, nil
+}
+
+// SlippageBps returns the cost of filling size against side relative to
+// the mid, in basis points. It is positive when the fill is worse than the
+// mid. Sizes are validated like VWAP.
+func (d Depth) SlippageBps(side Side, size float64) (float64, error) {
+	if !(size > 0) {
+		return 0, ErrInvalidSize
+	}
+	mid, ok := d.Mid()
+	if !ok {
+		return 0, ErrEmptyBook
+	}
+	price, err := d.VWAP(side, size)
+	if err != nil {
+		return 0, err
+	}
+	slippage := (price - mid) / mid * 10000
+	if side == Bids {
+		slippage = -slippage
+	}
+	return slippage, nil
+}
+
+// Microprice returns the mid weighted by the size at the top of the book,
+// which leans towards the side with less size.
+func (d Depth) Microprice() (float64, bool) {
+	if len(d.Bids) == 0 || len(d.Asks) == 0 {
+		return 0, false
+	}
+	bid, ask := d.Bids[0], d.Asks[0]
+	total := bid.Amount + ask.Amount
+	if total == 0 {
+		return (bid.Price + ask.Price) / 2, true
+	}
+	return (bid.Price*ask.Amount + ask.Price*bid.Amount) / total, true
+}
+
+// Imbalance returns (bids - asks) / (bids + asks) over the amounts of the
+// top n levels of each side, from -1 (only asks) to 1 (only bids). n <= 0
+// uses the whole book.
+func (d Depth) Imbalance(n int) float64 {
+	bids := sum(top(d.Bids, n))
+	asks := sum(top(d.Asks, n))
+	if bids+asks == 0 {
+		return 0
+	}
+	return (bids - asks) / (bids + asks)
+}
+
+// SpreadTicks returns the spread in ticks of instrument, using the tick
+// size at the best bid.
+func (d Depth) SpreadTicks(instrument models.Instrument) (float64, bool) {
+	if len(d.Bids) == 0 || len(d.Asks) == 0 {
+		return 0, false
+	}
+	bid := decimal.NewFromFloat(d.Bids[0].Price)
+	ask := decimal.NewFromFloat(d.Asks[0].Price)
+	tick := instruments.TickSize(instrument, bid)
+	if !tick.IsPositive() {
+		return 0, false
+	}
+	return ask.Sub(bid).Div(tick).InexactFloat64(), true
+}
+
+// DepthWithin returns the cumulative amount of each side priced within
+// percent of the mid, e.g. 0.5 for the levels within 0.5% of it. It fails
+// with ErrInvalidPercent unless percent is positive.
+func (d Depth) DepthWithin(percent float64) (bids, asks float64, err error) {
+	if !(percent > 0) {
+		return 0, 0, ErrInvalidPercent
+	}
+	mid, ok := d.Mid()
+	if !ok {
+		return 0, 0, ErrEmptyBook
+	}
+	low, high := mid*(1-percent/100), mid*(1+percent/100)
+	for _, level := range d.Bids {
+		if level.Price < low {
+			break
+		}
+		bids += level.Amount
+	}
+	for _, level := range d.Asks {
+		if level.Price > high {
+			break
+		}
+		asks += level.Amount
+	}
+	return bids, asks, nil
+}
+
+func sum(levels []Level) float64 {
+	var total float64
+	for _, level := range levels {
+		total += level.Amount
+	}
+	return total
+}
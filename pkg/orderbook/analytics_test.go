@@ -0,0 +1,144 @@
+package orderbook
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+var testDepth = Depth{
+	Bids: []Level{{100, 2}, {99, 3}, {98, 5}},
+	Asks: []Level{{101, 1}, {102, 4}, {104, 10}},
+}
+
+func TestDepth_VWAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		depth   Depth
+		side    Side
+		size    float64
+		want    float64
+		wantErr error
+	}{
+		{"top level", testDepth, Asks, 1, 101, nil},
+		{"two levels", testDepth, Asks, 3, (101 + 2*102) / 3.0, nil},
+		{"bids", testDepth, Bids, 4, (2*100 + 2*99) / 4.0, nil},
+		{"whole side", testDepth, Bids, 10, (2*100 + 3*99 + 5*98) / 10.0, nil},
+		{"too thin", testDepth, Asks, 16, 0, ErrInsufficientDepth},
+		{"empty", Depth{}, Bids, 1, 0, ErrEmptyBook},
+		{"zero size", testDepth, Asks, 0, 0, ErrInvalidSize},
+		{"negative size", testDepth, Bids, -1, 0, ErrInvalidSize},
+		{"NaN size", testDepth, Asks, math.NaN(), 0, ErrInvalidSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.depth.VWAP(tt.side, tt.size)
+			assert.Equal(t, tt.wantErr, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestDepth_AverageFillPrice(t *testing.T) {
+	price, filled := testDepth.AverageFillPrice(Asks, 20)
+	assert.Equal(t, 15.0, filled)
+	assert.InDelta(t, (101+4*102+10*104)/15.0, price, 1e-9)
+
+	price, filled = Depth{}.AverageFillPrice(Bids, 1)
+	assert.Zero(t, price)
+	assert.Zero(t, filled)
+}
+
+func TestDepth_SlippageBps(t *testing.T) {
+	// mid 100.5
+	buy, err := testDepth.SlippageBps(Asks, 1)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5/100.5*10000, buy, 1e-9)
+
+	sell, err := testDepth.SlippageBps(Bids, 5)
+	assert.NoError(t, err)
+	assert.InDelta(t, (100.5-99.4)/100.5*10000, sell, 1e-9)
+
+	_, err = Depth{Bids: testDepth.Bids}.SlippageBps(Bids, 1)
+	assert.Equal(t, ErrEmptyBook, err)
+	_, err = testDepth.SlippageBps(Asks, 100)
+	assert.Equal(t, ErrInsufficientDepth, err)
+	_, err = testDepth.SlippageBps(Asks, 0)
+	assert.Equal(t, ErrInvalidSize, err)
+}
+
+func TestDepth_Microprice(t *testing.T) {
+	micro, ok := testDepth.Microprice()
+	assert.True(t, ok)
+	// More size on the bid pushes the price towards the ask.
+	assert.InDelta(t, (100*1+101*2)/3.0, micro, 1e-9)
+
+	_, ok = Depth{Asks: testDepth.Asks}.Microprice()
+	assert.False(t, ok)
+}
+
+func TestDepth_Imbalance(t *testing.T) {
+	tests := []struct {
+		name  string
+		depth Depth
+		n     int
+		want  float64
+	}{
+		{"top", testDepth, 1, (2 - 1) / 3.0},
+		{"top two", testDepth, 2, (5 - 5) / 10.0},
+		{"all", testDepth, 0, (10 - 15) / 25.0},
+		{"only bids", Depth{Bids: testDepth.Bids}, 0, 1},
+		{"empty", Depth{}, 5, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, tt.depth.Imbalance(tt.n), 1e-9)
+		})
+	}
+}
+
+func TestDepth_SpreadTicks(t *testing.T) {
+	instrument := models.Instrument{TickSize: 0.5}
+	ticks, ok := testDepth.SpreadTicks(instrument)
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, ticks)
+
+	option := models.Instrument{
+		TickSize:      0.0001,
+		TickSizeSteps: []models.TickSizeStep{{AbovePrice: 0.005, TickSize: 0.0005}},
+	}
+	ticks, _ = Depth{Bids: []Level{{0.0105, 1}}, Asks: []Level{{0.0115, 1}}}.SpreadTicks(option)
+	assert.Equal(t, 2.0, ticks)
+
+	_, ok = Depth{}.SpreadTicks(instrument)
+	assert.False(t, ok)
+}
+
+func TestDepth_DepthWithin(t *testing.T) {
+	// mid 100.5: 1% is [99.495, 101.505], 2% is [98.49, 102.51]
+	bids, asks, err := testDepth.DepthWithin(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, bids)
+	assert.Equal(t, 1.0, asks)
+
+	bids, asks, err = testDepth.DepthWithin(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, bids)
+	assert.Equal(t, 5.0, asks)
+
+	for _, percent := range []float64{0, -1, math.NaN()} {
+		_, _, err = testDepth.DepthWithin(percent)
+		assert.Equal(t, ErrInvalidPercent, err, percent)
+	}
+	_, _, err = Depth{Bids: testDepth.Bids}.DepthWithin(1)
+	assert.Equal(t, ErrEmptyBook, err)
+}
+
+func TestBook_Depth(t *testing.T) {
+	b := newBook("BTC-PERPETUAL")
+	b.reset([][]float64{{100, 2}, {99, 3}, {98, 5}}, [][]float64{{101, 1}, {102, 4}, {104, 10}}, 1, b.timestamp)
+	assert.Equal(t, testDepth, b.Depth(0))
+	assert.Equal(t, Depth{Bids: []Level{{100, 2}}, Asks: []Level{{101, 1}}}, b.Depth(1))
+}
@@ -0,0 +1,186 @@
+// Package orderbook maintains local L2 order books from the incremental
+// book.{instrument}.raw and book.{instrument}.{interval} notifications.
+//
+// A Manager applies every notification to the book of its instrument and
+// checks that its prev_change_id continues the book. When a notification is
+// missed the book resyncs itself from a GetOrderBook snapshot, replaying the
+// notifications that arrive while the snapshot is fetched:
+//
+//	books := orderbook.New(client, orderbook.Config{
+//		OnUpdate: func(b *orderbook.Book) {
+//			bid, _ := b.BestBid()
+//			ask, _ := b.BestAsk()
+//			log.Printf("%s %v / %v", b.Instrument(), bid.Price, ask.Price)
+//		},
+//	})
+//	defer books.Close()
+//	sub, err := client.OnBookRaw("BTC-PERPETUAL", books.Apply)
+//
+// Snapshot is the one value a book is handed out as, whether it comes from
+// a live Book, the grouped book.{instrument}.{group}.{depth}.{interval}
+// channels or a REST order book; Aggregate groups it into price buckets
+// locally. Its Depth holds the analytics, such as VWAP, SlippageBps,
+// Microprice and Imbalance.
+package orderbook
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// Level is the total amount resting at a price.
+type Level struct {
+	Price  float64
+	Amount float64
+}
+
+// Book is the L2 order book of one instrument. Its methods are safe for
+// concurrent use.
+type Book struct {
+	instrument string
+
+	mu        sync.RWMutex
+	bids      []Level // best, i.e. highest, first
+	asks      []Level // best, i.e. lowest, first
+	changeID  int64
+	timestamp time.Time
+	synced    bool
+
+	// resync state, guarded by mu
+	resyncing bool
+	pending   []delta
+	gen       int
+}
+
+func newBook(instrument string) *Book {
+	return &Book{instrument: instrument}
+}
+
+func (b *Book) Instrument() string {
+	return b.instrument
+}
+
+// BestBid returns the highest bid, if any.
+func (b *Book) BestBid() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.bids) == 0 {
+		return Level{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask, if any.
+func (b *Book) BestAsk() (Level, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.asks) == 0 {
+		return Level{}, false
+	}
+	return b.asks[0], true
+}
+
+// Bids returns up to depth bids, best first. depth <= 0 returns all.
+func (b *Book) Bids(depth int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids, depth)
+}
+
+// Asks returns up to depth asks, best first. depth <= 0 returns all.
+func (b *Book) Asks(depth int) []Level {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.asks, depth)
+}
+
+// ChangeID returns the change_id of the last applied update.
+func (b *Book) ChangeID() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.changeID
+}
+
+// Timestamp returns the server time of the last applied update.
+func (b *Book) Timestamp() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.timestamp
+}
+
+// Synced reports whether the book is consistent with the server. It is
+// false until the first snapshot and while resyncing after a gap.
+func (b *Book) Synced() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.synced
+}
+
+func top(levels []Level, depth int) []Level {
+	if depth <= 0 || depth > len(levels) {
+		depth = len(levels)
+	}
+	return append([]Level(nil), levels[:depth]...)
+}
+
+// reset replaces the book with a snapshot. Callers hold b.mu.
+func (b *Book) reset(bids, asks [][]float64, changeID int64, timestamp time.Time) {
+	b.bids, b.asks = b.bids[:0], b.asks[:0]
+	for _, level := range bids {
+		if len(level) >= 2 {
+			b.bids = set(b.bids, level[0], level[1], true)
+		}
+	}
+	for _, level := range asks {
+		if len(level) >= 2 {
+			b.asks = set(b.asks, level[0], level[1], false)
+		}
+	}
+	b.changeID = changeID
+	b.timestamp = timestamp
+}
+
+// apply applies the items of d. Callers hold b.mu.
+func (b *Book) apply(d delta) {
+	for _, item := range d.bids {
+		b.bids = update(b.bids, item, true)
+	}
+	for _, item := range d.asks {
+		b.asks = update(b.asks, item, false)
+	}
+	b.changeID = d.changeID
+	b.timestamp = d.timestamp
+}
+
+func update(levels []Level, item models.OrderBookNotificationItem, descending bool) []Level {
+	if item.Action == "delete" {
+		return set(levels, item.Price, 0, descending)
+	}
+	// "new" and "change" both set the amount at the price.
+	return set(levels, item.Price, item.Amount, descending)
+}
+
+// set sets the amount at price, removing the level when amount is zero.
+func set(levels []Level, price, amount float64, descending bool) []Level {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price <= price
+		}
+		return levels[i].Price >= price
+	})
+	found := i < len(levels) && levels[i].Price == price
+	switch {
+	case found && amount == 0:
+		return append(levels[:i], levels[i+1:]...)
+	case found:
+		levels[i].Amount = amount
+	case amount != 0:
+		levels = append(levels, Level{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = Level{Price: price, Amount: amount}
+	}
+	return levels
+}
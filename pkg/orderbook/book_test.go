@@ -0,0 +1,59 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name       string
+		levels     []Level
+		price      float64
+		amount     float64
+		descending bool
+		want       []Level
+	}{
+		{"insert into empty", nil, 10, 1, false, []Level{{10, 1}}},
+		{"insert ascending", []Level{{10, 1}, {12, 1}}, 11, 2, false, []Level{{10, 1}, {11, 2}, {12, 1}}},
+		{"insert descending", []Level{{12, 1}, {10, 1}}, 11, 2, true, []Level{{12, 1}, {11, 2}, {10, 1}}},
+		{"append worst bid", []Level{{12, 1}}, 10, 2, true, []Level{{12, 1}, {10, 2}}},
+		{"replace", []Level{{10, 1}, {11, 1}}, 11, 5, false, []Level{{10, 1}, {11, 5}}},
+		{"remove", []Level{{10, 1}, {11, 1}}, 10, 0, false, []Level{{11, 1}}},
+		{"remove missing", []Level{{10, 1}}, 11, 0, false, []Level{{10, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, set(tt.levels, tt.price, tt.amount, tt.descending))
+		})
+	}
+}
+
+func TestBook(t *testing.T) {
+	b := newBook("BTC-PERPETUAL")
+	_, ok := b.BestBid()
+	assert.False(t, ok)
+
+	b.reset([][]float64{{100, 1}, {101, 2}, {99, 3}}, [][]float64{{103, 1}, {102, 4}}, 7, b.timestamp)
+	b.apply(delta{
+		changeID: 8,
+		bids: []models.OrderBookNotificationItem{
+			{Action: "new", Price: 101.5, Amount: 1},
+			{Action: "delete", Price: 99, Amount: 0},
+			{Action: "change", Price: 100, Amount: 6},
+		},
+		asks: []models.OrderBookNotificationItem{{Action: "delete", Price: 102}},
+	})
+
+	bid, ok := b.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, Level{101.5, 1}, bid)
+	ask, _ := b.BestAsk()
+	assert.Equal(t, Level{103, 1}, ask)
+	assert.Equal(t, []Level{{101.5, 1}, {101, 2}}, b.Bids(2))
+	assert.Equal(t, []Level{{101.5, 1}, {101, 2}, {100, 6}}, b.Bids(0))
+	assert.Equal(t, []Level{{103, 1}}, b.Asks(10))
+	assert.Equal(t, int64(8), b.ChangeID())
+}
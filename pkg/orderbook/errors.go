@@ -0,0 +1,20 @@
+package orderbook
+
+import "fmt"
+
+// GapError reports a notification that does not continue the book.
+type GapError struct {
+	Instrument string
+	// ChangeID is the change_id of the book.
+	ChangeID int64
+	// PrevChangeID is the prev_change_id of the notification, or -1 when
+	// a snapshot did not reach the buffered notifications.
+	PrevChangeID int64
+}
+
+func (e *GapError) Error() string {
+	if e.PrevChangeID < 0 {
+		return fmt.Sprintf("%s: snapshot %d does not reach the buffered updates", e.Instrument, e.ChangeID)
+	}
+	return fmt.Sprintf("%s: update continues change %d, book is at %d", e.Instrument, e.PrevChangeID, e.ChangeID)
+}
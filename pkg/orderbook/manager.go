@@ -0,0 +1,264 @@
+package orderbook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// Source fetches order book snapshots. Both the REST and the WebSocket
+// client implement it.
+type Source interface {
+	GetOrderBookContext(ctx context.Context, params *models.GetOrderBookParams) (models.GetOrderBookResponse, error)
+}
+
+// Config configures a Manager.
+type Config struct {
+	// SnapshotDepth is the depth of the resync snapshots. Zero means
+	// 10000, the full book.
+	SnapshotDepth int
+	// Backoff spaces out failed snapshot requests. The zero value means
+	// 100ms growing to 10s.
+	Backoff deribit.Backoff
+	// OnUpdate, if set, is called after every change of a synced book.
+	OnUpdate func(b *Book)
+	// OnResync, if set, is called when a book detects a gap and when a
+	// snapshot request fails.
+	OnResync func(instrument string, err error)
+}
+
+const (
+	defaultSnapshotDepth = 10000
+	// maxPending bounds the deltas buffered during a resync. Dropping the
+	// oldest is safe: a snapshot newer than them no longer needs them.
+	maxPending = 10000
+)
+
+// delta is one notification in the form shared by both book channels.
+type delta struct {
+	snapshot     bool
+	prevChangeID int64
+	changeID     int64
+	timestamp    time.Time
+	bids, asks   []models.OrderBookNotificationItem
+}
+
+// Manager keeps the books of any number of instruments. It is safe for
+// concurrent use.
+type Manager struct {
+	src    Source
+	cfg    Config
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	books map[string]*Book
+}
+
+// New returns a Manager that resyncs books from src.
+func New(src Source, cfg Config) *Manager {
+	if cfg.SnapshotDepth <= 0 {
+		cfg.SnapshotDepth = defaultSnapshotDepth
+	}
+	if cfg.Backoff.InitialInterval <= 0 {
+		cfg.Backoff = deribit.Backoff{
+			InitialInterval: 100 * time.Millisecond,
+			MaxInterval:     10 * time.Second,
+			Multiplier:      2,
+			Jitter:          0.2,
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		src:    src,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		books:  make(map[string]*Book),
+	}
+}
+
+// Book returns the book of instrument, or nil before its first
+// notification.
+func (m *Manager) Book(instrument string) *Book {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.books[instrument]
+}
+
+// Close stops pending resyncs.
+func (m *Manager) Close() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// Apply applies a book.{instrument}.raw notification. The first
+// notification of a subscription carries no prev_change_id and is the
+// snapshot.
+func (m *Manager) Apply(n *models.OrderBookRawNotification) {
+	m.apply(n.InstrumentName, delta{
+		snapshot:     n.PrevChangeID == 0,
+		prevChangeID: n.PrevChangeID,
+		changeID:     n.ChangeID,
+		timestamp:    time.UnixMilli(n.Timestamp),
+		bids:         n.Bids,
+		asks:         n.Asks,
+	})
+}
+
+// ApplyBook applies a book.{instrument}.{interval} notification.
+func (m *Manager) ApplyBook(n *models.OrderBookNotification) {
+	m.apply(n.InstrumentName, delta{
+		snapshot:     n.Type == "snapshot",
+		prevChangeID: n.PrevChangeID,
+		changeID:     n.ChangeID,
+		timestamp:    time.UnixMilli(n.Timestamp),
+		bids:         n.Bids,
+		asks:         n.Asks,
+	})
+}
+
+func (m *Manager) book(instrument string) *Book {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.books[instrument]
+	if !ok {
+		b = newBook(instrument)
+		m.books[instrument] = b
+	}
+	return b
+}
+
+func (m *Manager) apply(instrument string, d delta) {
+	b := m.book(instrument)
+
+	b.mu.Lock()
+	switch {
+	case d.snapshot:
+		// A new subscription starts over and supersedes any resync.
+		b.reset(nil, nil, 0, d.timestamp)
+		b.apply(d)
+		b.synced = true
+		b.resyncing = false
+		b.pending = nil
+		b.gen++
+	case b.resyncing:
+		if len(b.pending) >= maxPending {
+			b.pending = append(b.pending[:0], b.pending[1:]...)
+		}
+		b.pending = append(b.pending, d)
+		b.mu.Unlock()
+		return
+	case b.synced && d.changeID <= b.changeID:
+		// Already in the book, as after a snapshot that was ahead of the
+		// stream.
+		b.mu.Unlock()
+		return
+	case !b.synced || d.prevChangeID != b.changeID:
+		gap := &GapError{Instrument: instrument, ChangeID: b.changeID, PrevChangeID: d.prevChangeID}
+		b.synced = false
+		b.resyncing = true
+		b.pending = []delta{d}
+		b.gen++
+		gen := b.gen
+		b.mu.Unlock()
+		m.report(instrument, gap)
+		m.wg.Add(1)
+		go m.resync(b, gen)
+		return
+	default:
+		b.apply(d)
+	}
+	b.mu.Unlock()
+	m.updated(b)
+}
+
+// resync loads a snapshot into b and replays the pending deltas, retrying
+// until the deltas continue the snapshot or b is superseded.
+func (m *Manager) resync(b *Book, gen int) {
+	defer m.wg.Done()
+
+	for attempt := 0; ; attempt++ {
+		snapshot, err := m.src.GetOrderBookContext(m.ctx, &models.GetOrderBookParams{
+			InstrumentName: b.instrument,
+			Depth:          m.cfg.SnapshotDepth,
+		})
+		if m.ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			done, stale := m.load(b, gen, snapshot)
+			if stale {
+				return
+			}
+			if done {
+				m.updated(b)
+				return
+			}
+			err = &GapError{Instrument: b.instrument, ChangeID: int64(snapshot.ChangeID), PrevChangeID: -1}
+		}
+		m.report(b.instrument, err)
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(m.cfg.Backoff.Duration(attempt)):
+		}
+	}
+}
+
+// load applies snapshot and the pending deltas to b. It reports stale when
+// b no longer waits for generation gen, and not done when the pending
+// deltas do not continue the snapshot.
+func (m *Manager) load(b *Book, gen int, snapshot models.GetOrderBookResponse) (done, stale bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.gen != gen || !b.resyncing {
+		return false, true
+	}
+
+	changeID := int64(snapshot.ChangeID)
+	pending := b.pending[:0]
+	for _, d := range b.pending {
+		if d.changeID > changeID {
+			pending = append(pending, d)
+		}
+	}
+	b.pending = pending
+	// The snapshot is older than the oldest delta we hold.
+	if len(pending) > 0 && pending[0].prevChangeID != changeID {
+		return false, false
+	}
+	for i := 1; i < len(pending); i++ {
+		if pending[i].prevChangeID != pending[i-1].changeID {
+			// A gap between buffered deltas: only a newer snapshot helps.
+			b.pending = pending[i:]
+			return false, false
+		}
+	}
+
+	b.reset(snapshot.Bids, snapshot.Asks, changeID, time.UnixMilli(snapshot.Timestamp))
+	for _, d := range pending {
+		b.apply(d)
+	}
+	b.synced = true
+	b.resyncing = false
+	b.pending = nil
+	return true, false
+}
+
+func (m *Manager) updated(b *Book) {
+	if m.cfg.OnUpdate != nil {
+		m.cfg.OnUpdate(b)
+	}
+}
+
+func (m *Manager) report(instrument string, err error) {
+	if m.cfg.OnResync != nil {
+		m.cfg.OnResync(instrument, err)
+	}
+}
@@ -0,0 +1,252 @@
+package orderbook
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+type snapshotResult struct {
+	book models.GetOrderBookResponse
+	err  error
+}
+
+// fakeSource hands out the snapshots sent on results, one per request.
+type fakeSource struct {
+	requests chan *models.GetOrderBookParams
+	results  chan snapshotResult
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		requests: make(chan *models.GetOrderBookParams, 10),
+		results:  make(chan snapshotResult, 10),
+	}
+}
+
+func (s *fakeSource) GetOrderBookContext(ctx context.Context, params *models.GetOrderBookParams) (models.GetOrderBookResponse, error) {
+	s.requests <- params
+	select {
+	case r := <-s.results:
+		return r.book, r.err
+	case <-ctx.Done():
+		return models.GetOrderBookResponse{}, ctx.Err()
+	}
+}
+
+func snapshot(changeID int, bids, asks [][]float64) snapshotResult {
+	return snapshotResult{book: models.GetOrderBookResponse{ChangeID: changeID, Bids: bids, Asks: asks}}
+}
+
+func raw(prev, change int64, bids, asks []models.OrderBookNotificationItem) *models.OrderBookRawNotification {
+	return &models.OrderBookRawNotification{
+		InstrumentName: "BTC-PERPETUAL",
+		PrevChangeID:   prev,
+		ChangeID:       change,
+		Bids:           bids,
+		Asks:           asks,
+	}
+}
+
+func item(action string, price, amount float64) []models.OrderBookNotificationItem {
+	return []models.OrderBookNotificationItem{{Action: action, Price: price, Amount: amount}}
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	updates []int64
+	errs    []error
+	synced  chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{synced: make(chan struct{}, 10)}
+}
+
+func (r *recorder) config() Config {
+	return Config{
+		Backoff: deribit.Backoff{InitialInterval: time.Millisecond},
+		OnUpdate: func(b *Book) {
+			r.mu.Lock()
+			r.updates = append(r.updates, b.ChangeID())
+			r.mu.Unlock()
+			r.synced <- struct{}{}
+		},
+		OnResync: func(instrument string, err error) {
+			r.mu.Lock()
+			r.errs = append(r.errs, err)
+			r.mu.Unlock()
+		},
+	}
+}
+
+func (r *recorder) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-r.synced:
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update")
+	}
+}
+
+func TestManager_Apply(t *testing.T) {
+	rec := newRecorder()
+	m := New(newFakeSource(), rec.config())
+	defer m.Close()
+	assert.Nil(t, m.Book("BTC-PERPETUAL"))
+
+	m.Apply(raw(0, 10, item("new", 100, 1), item("new", 101, 2)))
+	m.Apply(raw(10, 11, item("new", 100.5, 3), nil))
+	m.Apply(raw(11, 12, nil, item("change", 101, 5)))
+
+	b := m.Book("BTC-PERPETUAL")
+	require.NotNil(t, b)
+	assert.True(t, b.Synced())
+	bid, _ := b.BestBid()
+	ask, _ := b.BestAsk()
+	assert.Equal(t, Level{100.5, 3}, bid)
+	assert.Equal(t, Level{101, 5}, ask)
+	assert.Equal(t, []int64{10, 11, 12}, rec.updates)
+	assert.Empty(t, rec.errs)
+}
+
+func TestManager_ApplyBook(t *testing.T) {
+	rec := newRecorder()
+	m := New(newFakeSource(), rec.config())
+	defer m.Close()
+
+	m.ApplyBook(&models.OrderBookNotification{Type: "snapshot", InstrumentName: "ETH-PERPETUAL", ChangeID: 5, Bids: item("new", 3000, 1)})
+	m.ApplyBook(&models.OrderBookNotification{Type: "change", InstrumentName: "ETH-PERPETUAL", PrevChangeID: 5, ChangeID: 6, Bids: item("delete", 3000, 0)})
+
+	_, ok := m.Book("ETH-PERPETUAL").BestBid()
+	assert.False(t, ok)
+	assert.Equal(t, int64(6), m.Book("ETH-PERPETUAL").ChangeID())
+}
+
+func TestManager_ResyncOnGap(t *testing.T) {
+	src := newFakeSource()
+	rec := newRecorder()
+	m := New(src, rec.config())
+	defer m.Close()
+
+	m.Apply(raw(0, 10, item("new", 100, 1), item("new", 101, 1)))
+	rec.wait(t)
+
+	// Change 11 is lost.
+	m.Apply(raw(11, 12, item("new", 99, 1), nil))
+	params := <-src.requests
+	assert.Equal(t, &models.GetOrderBookParams{InstrumentName: "BTC-PERPETUAL", Depth: 10000}, params)
+	assert.False(t, m.Book("BTC-PERPETUAL").Synced())
+
+	// Arrives while the snapshot is fetched.
+	m.Apply(raw(12, 13, item("new", 98, 1), nil))
+	m.Apply(raw(13, 14, nil, item("delete", 102, 0)))
+	src.results <- snapshot(12, [][]float64{{100, 1}, {99, 1}}, [][]float64{{101, 1}, {102, 2}})
+	rec.wait(t)
+
+	b := m.Book("BTC-PERPETUAL")
+	assert.True(t, b.Synced())
+	assert.Equal(t, int64(14), b.ChangeID())
+	assert.Equal(t, []Level{{100, 1}, {99, 1}, {98, 1}}, b.Bids(0))
+	assert.Equal(t, []Level{{101, 1}}, b.Asks(0))
+
+	var gap *GapError
+	if assert.Len(t, rec.errs, 1) && assert.True(t, errors.As(rec.errs[0], &gap)) {
+		assert.Equal(t, int64(10), gap.ChangeID)
+		assert.Equal(t, int64(11), gap.PrevChangeID)
+	}
+
+	// The book continues from the replayed deltas.
+	m.Apply(raw(14, 15, item("change", 100, 4), nil))
+	rec.wait(t)
+	bid, _ := b.BestBid()
+	assert.Equal(t, Level{100, 4}, bid)
+}
+
+func TestManager_SnapshotAheadOfStream(t *testing.T) {
+	src := newFakeSource()
+	rec := newRecorder()
+	m := New(src, rec.config())
+	defer m.Close()
+
+	m.Apply(raw(0, 10, item("new", 100, 1), nil))
+	rec.wait(t)
+
+	// Change 11 is lost; 12 is buffered while the snapshot is fetched.
+	m.Apply(raw(11, 12, item("new", 99, 1), nil))
+	<-src.requests
+	// The snapshot already holds changes up to 14.
+	src.results <- snapshot(14, [][]float64{{100, 1}, {99, 1}, {98, 1}}, nil)
+	rec.wait(t)
+
+	// Deltas still in flight are dropped, not reported as gaps.
+	m.Apply(raw(12, 13, item("new", 98, 1), nil))
+	m.Apply(raw(13, 14, item("new", 97, 5), nil))
+	m.Apply(raw(14, 15, item("change", 100, 4), nil))
+	rec.wait(t)
+
+	b := m.Book("BTC-PERPETUAL")
+	assert.True(t, b.Synced())
+	assert.Equal(t, int64(15), b.ChangeID())
+	assert.Equal(t, []Level{{100, 4}, {99, 1}, {98, 1}}, b.Bids(0))
+	assert.Empty(t, src.requests)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Len(t, rec.errs, 1)
+}
+
+func TestManager_ResyncRetries(t *testing.T) {
+	src := newFakeSource()
+	rec := newRecorder()
+	m := New(src, rec.config())
+	defer m.Close()
+
+	// No snapshot notification yet, so the book starts with a resync.
+	m.Apply(raw(20, 21, item("new", 100, 1), nil))
+	<-src.requests
+	src.results <- snapshotResult{err: errors.New("unavailable")}
+
+	// Too old: it does not reach change 20.
+	<-src.requests
+	src.results <- snapshot(18, nil, nil)
+
+	<-src.requests
+	src.results <- snapshot(21, [][]float64{{100, 1}}, nil)
+	rec.wait(t)
+
+	b := m.Book("BTC-PERPETUAL")
+	assert.True(t, b.Synced())
+	assert.Equal(t, int64(21), b.ChangeID())
+	assert.Equal(t, []Level{{100, 1}}, b.Bids(0))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Len(t, rec.errs, 3)
+}
+
+func TestManager_SnapshotSupersedesResync(t *testing.T) {
+	src := newFakeSource()
+	rec := newRecorder()
+	m := New(src, rec.config())
+	defer m.Close()
+
+	m.Apply(raw(5, 6, item("new", 100, 1), nil))
+	<-src.requests
+
+	// A resubscription delivers a fresh snapshot first.
+	m.Apply(raw(0, 30, item("new", 200, 1), nil))
+	rec.wait(t)
+	src.results <- snapshot(6, [][]float64{{100, 1}}, nil)
+
+	m.Close()
+	b := m.Book("BTC-PERPETUAL")
+	assert.Equal(t, int64(30), b.ChangeID())
+	assert.Equal(t, []Level{{200, 1}}, b.Bids(0))
+}
@@ -0,0 +1,107 @@
+package orderbook
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// Snapshot is the public value of a book at one point in time together
+// with where it came from. Live books, grouped channels, local aggregations
+// and, through restmodels.OrderBook.Snapshot and restmodels.FromSnapshot,
+// REST order books all convert to it; Depth is just its levels.
+type Snapshot struct {
+	Depth
+	Instrument string
+	ChangeID   int64
+	Timestamp  time.Time
+	// Group is the price bucket the levels are grouped into: "none" or
+	// empty for individual prices, otherwise a price step such as "5".
+	Group string
+	// Levels is the number of levels each side was limited to, zero for
+	// no limit.
+	Levels int
+}
+
+// FromGroupNotification converts a notification of
+// book.{instrument}.{group}.{depth}.{interval}, or of
+// book.{instrument}.{depth}.{interval} with an empty group. The channel
+// name carries group and depth, the notification does not.
+func FromGroupNotification(n *models.OrderBookGroupNotification, group string, depth int) Snapshot {
+	return Snapshot{
+		Depth:      Depth{Bids: levels(n.Bids), Asks: levels(n.Asks)},
+		Instrument: n.InstrumentName,
+		ChangeID:   n.ChangeID,
+		Timestamp:  time.UnixMilli(n.Timestamp),
+		Group:      group,
+		Levels:     depth,
+	}
+}
+
+// Snapshot returns up to n levels of each side with the book's change id
+// and time. n <= 0 returns the whole book.
+func (b *Book) Snapshot(n int) Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if n < 0 {
+		n = 0
+	}
+	return Snapshot{
+		Depth:      Depth{Bids: top(b.bids, n), Asks: top(b.asks, n)},
+		Instrument: b.instrument,
+		ChangeID:   b.changeID,
+		Timestamp:  b.timestamp,
+		Levels:     n,
+	}
+}
+
+// Aggregate groups s into price buckets of size bucket, like the grouped
+// channels do.
+func (s Snapshot) Aggregate(bucket float64) Snapshot {
+	s.Depth = s.Depth.Aggregate(bucket)
+	if bucket > 0 {
+		s.Group = strconv.FormatFloat(bucket, 'f', -1, 64)
+	}
+	return s
+}
+
+// Aggregate sums the levels of d into price buckets of size bucket. Bids
+// are rounded down and asks up to a multiple of bucket, so a bucket never
+// looks better than the prices in it. bucket <= 0 returns a copy of d.
+func (d Depth) Aggregate(bucket float64) Depth {
+	if bucket <= 0 {
+		return Depth{Bids: top(d.Bids, 0), Asks: top(d.Asks, 0)}
+	}
+	step := decimal.NewFromFloat(bucket)
+	return Depth{
+		Bids: aggregate(d.Bids, step, decimal.Decimal.Floor),
+		Asks: aggregate(d.Asks, step, decimal.Decimal.Ceil),
+	}
+}
+
+// aggregate merges sorted levels; round keeps the bucket prices in the
+// order of the levels.
+func aggregate(levels []Level, step decimal.Decimal, round func(decimal.Decimal) decimal.Decimal) []Level {
+	var result []Level
+	for _, level := range levels {
+		price := round(decimal.NewFromFloat(level.Price).Div(step)).Mul(step).InexactFloat64()
+		if n := len(result); n > 0 && result[n-1].Price == price {
+			result[n-1].Amount += level.Amount
+			continue
+		}
+		result = append(result, Level{Price: price, Amount: level.Amount})
+	}
+	return result
+}
+
+func levels(raw [][]float64) []Level {
+	result := make([]Level, 0, len(raw))
+	for _, level := range raw {
+		if len(level) >= 2 {
+			result = append(result, Level{Price: level[0], Amount: level[1]})
+		}
+	}
+	return result
+}
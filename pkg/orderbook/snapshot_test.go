@@ -0,0 +1,92 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+func TestFromGroupNotification(t *testing.T) {
+	n := &models.OrderBookGroupNotification{
+		Timestamp:      1700000000000,
+		InstrumentName: "ETH-PERPETUAL",
+		ChangeID:       42,
+		Bids:           [][]float64{{3000, 5}, {2999, 1}, {2998}},
+		Asks:           [][]float64{{3001, 2}},
+	}
+	s := FromGroupNotification(n, "1", 10)
+	assert.Equal(t, Snapshot{
+		Depth: Depth{
+			Bids: []Level{{3000, 5}, {2999, 1}},
+			Asks: []Level{{3001, 2}},
+		},
+		Instrument: "ETH-PERPETUAL",
+		ChangeID:   42,
+		Timestamp:  time.UnixMilli(1700000000000),
+		Group:      "1",
+		Levels:     10,
+	}, s)
+
+	// The analytics work on grouped books too.
+	mid, _ := s.Mid()
+	assert.Equal(t, 3000.5, mid)
+}
+
+func TestDepth_Aggregate(t *testing.T) {
+	d := Depth{
+		Bids: []Level{{100.3, 1}, {100.1, 2}, {99.9, 4}, {97.5, 1}},
+		Asks: []Level{{100.4, 1}, {100.5, 2}, {100.6, 3}, {103, 1}},
+	}
+	tests := []struct {
+		name   string
+		bucket float64
+		want   Depth
+	}{
+		{
+			"half",
+			0.5,
+			Depth{
+				Bids: []Level{{100, 3}, {99.5, 4}, {97.5, 1}},
+				Asks: []Level{{100.5, 3}, {101, 3}, {103, 1}},
+			},
+		},
+		{
+			"tenth keeps prices",
+			0.1,
+			d,
+		},
+		{
+			"five",
+			5,
+			Depth{
+				Bids: []Level{{100, 3}, {95, 5}},
+				Asks: []Level{{105, 7}},
+			},
+		},
+		{"no bucket", 0, d},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, d.Aggregate(tt.bucket))
+		})
+	}
+
+	// The input is left untouched.
+	assert.Equal(t, Level{100.3, 1}, d.Bids[0])
+}
+
+func TestSnapshot_Aggregate(t *testing.T) {
+	b := newBook("BTC-PERPETUAL")
+	b.reset([][]float64{{60001, 1}, {60000.5, 2}}, [][]float64{{60002, 3}, {60009.5, 4}}, 3, time.UnixMilli(1))
+
+	s := b.Snapshot(0).Aggregate(10)
+	assert.Equal(t, "10", s.Group)
+	assert.Equal(t, int64(3), s.ChangeID)
+	assert.Equal(t, []Level{{60000, 3}}, s.Bids)
+	assert.Equal(t, []Level{{60010, 7}}, s.Asks)
+
+	assert.Equal(t, 1, b.Snapshot(1).Levels)
+	assert.Len(t, b.Snapshot(1).Asks, 1)
+}
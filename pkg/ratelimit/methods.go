@@ -0,0 +1,108 @@
+package ratelimit
+
+import "strings"
+
+// methods classifies the API methods. Classify falls back to
+// NonMatchingEngine for methods missing here.
+var methods = map[string]Bucket{
+	// Order entry.
+	"private/buy":                        MatchingEngine,
+	"private/cancel":                     MatchingEngine,
+	"private/cancel_all":                 MatchingEngine,
+	"private/cancel_all_by_currency":     MatchingEngine,
+	"private/cancel_all_by_instrument":   MatchingEngine,
+	"private/cancel_all_by_kind_or_type": MatchingEngine,
+	"private/cancel_by_label":            MatchingEngine,
+	"private/cancel_quotes":              MatchingEngine,
+	"private/close_position":             MatchingEngine,
+	"private/edit":                       MatchingEngine,
+	"private/edit_by_label":              MatchingEngine,
+	"private/mass_quote":                 MatchingEngine,
+	"private/sell":                       MatchingEngine,
+
+	// Everything else.
+	"private/cancel_transfer_by_id":                  NonMatchingEngine,
+	"private/cancel_withdrawal":                      NonMatchingEngine,
+	"private/change_subaccount_name":                 NonMatchingEngine,
+	"private/create_deposit_address":                 NonMatchingEngine,
+	"private/create_subaccount":                      NonMatchingEngine,
+	"private/disable_cancel_on_disconnect":           NonMatchingEngine,
+	"private/disable_tfa_for_subaccount":             NonMatchingEngine,
+	"private/enable_cancel_on_disconnect":            NonMatchingEngine,
+	"private/get_account_summary":                    NonMatchingEngine,
+	"private/get_current_deposit_address":            NonMatchingEngine,
+	"private/get_deposits":                           NonMatchingEngine,
+	"private/get_email_language":                     NonMatchingEngine,
+	"private/get_margins":                            NonMatchingEngine,
+	"private/get_new_announcements":                  NonMatchingEngine,
+	"private/get_open_orders_by_currency":            NonMatchingEngine,
+	"private/get_open_orders_by_instrument":          NonMatchingEngine,
+	"private/get_order_history_by_currency":          NonMatchingEngine,
+	"private/get_order_history_by_instrument":        NonMatchingEngine,
+	"private/get_order_margin_by_ids":                NonMatchingEngine,
+	"private/get_order_state":                        NonMatchingEngine,
+	"private/get_position":                           NonMatchingEngine,
+	"private/get_positions":                          NonMatchingEngine,
+	"private/get_settlement_history_by_currency":     NonMatchingEngine,
+	"private/get_settlement_history_by_instrument":   NonMatchingEngine,
+	"private/get_stop_order_history":                 NonMatchingEngine,
+	"private/get_subaccounts":                        NonMatchingEngine,
+	"private/get_subaccounts_details":                NonMatchingEngine,
+	"private/get_transfers":                          NonMatchingEngine,
+	"private/get_user_trades_by_currency":            NonMatchingEngine,
+	"private/get_user_trades_by_currency_and_time":   NonMatchingEngine,
+	"private/get_user_trades_by_instrument":          NonMatchingEngine,
+	"private/get_user_trades_by_instrument_and_time": NonMatchingEngine,
+	"private/get_user_trades_by_order":               NonMatchingEngine,
+	"private/get_withdrawals":                        NonMatchingEngine,
+	"private/logout":                                 NonMatchingEngine,
+	"private/set_announcement_as_read":               NonMatchingEngine,
+	"private/set_email_for_subaccount":               NonMatchingEngine,
+	"private/set_email_language":                     NonMatchingEngine,
+	"private/set_password_for_subaccount":            NonMatchingEngine,
+	"private/subscribe":                              NonMatchingEngine,
+	"private/toggle_notifications_from_subaccount":   NonMatchingEngine,
+	"private/toggle_subaccount_login":                NonMatchingEngine,
+	"private/unsubscribe":                            NonMatchingEngine,
+	"private/unsubscribe_all":                        NonMatchingEngine,
+	"private/withdraw":                               NonMatchingEngine,
+	"public/auth":                                    NonMatchingEngine,
+	"public/disable_heartbeat":                       NonMatchingEngine,
+	"public/get_announcements":                       NonMatchingEngine,
+	"public/get_book_summary_by_currency":            NonMatchingEngine,
+	"public/get_book_summary_by_instrument":          NonMatchingEngine,
+	"public/get_contract_size":                       NonMatchingEngine,
+	"public/get_currencies":                          NonMatchingEngine,
+	"public/get_funding_chart_data":                  NonMatchingEngine,
+	"public/get_funding_rate_value":                  NonMatchingEngine,
+	"public/get_historical_volatility":               NonMatchingEngine,
+	"public/get_index":                               NonMatchingEngine,
+	"public/get_instrument":                          NonMatchingEngine,
+	"public/get_instruments":                         NonMatchingEngine,
+	"public/get_last_settlements_by_currency":        NonMatchingEngine,
+	"public/get_last_settlements_by_instrument":      NonMatchingEngine,
+	"public/get_last_trades_by_currency":             NonMatchingEngine,
+	"public/get_last_trades_by_currency_and_time":    NonMatchingEngine,
+	"public/get_last_trades_by_instrument":           NonMatchingEngine,
+	"public/get_last_trades_by_instrument_and_time":  NonMatchingEngine,
+	"public/get_mark_price_history":                  NonMatchingEngine,
+	"public/get_order_book":                          NonMatchingEngine,
+	"public/get_time":                                NonMatchingEngine,
+	"public/get_trade_volumes":                       NonMatchingEngine,
+	"public/get_tradingview_chart_data":              NonMatchingEngine,
+	"public/hello":                                   NonMatchingEngine,
+	"public/set_heartbeat":                           NonMatchingEngine,
+	"public/subscribe":                               NonMatchingEngine,
+	"public/test":                                    NonMatchingEngine,
+	"public/ticker":                                  NonMatchingEngine,
+	"public/unsubscribe":                             NonMatchingEngine,
+	"public/unsubscribe_all":                         NonMatchingEngine,
+}
+
+// Classify returns the bucket a call to method draws from.
+func Classify(method string) Bucket {
+	if b, ok := methods[strings.TrimPrefix(method, "/")]; ok {
+		return b
+	}
+	return NonMatchingEngine
+}
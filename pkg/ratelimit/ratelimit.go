@@ -0,0 +1,230 @@
+// Package ratelimit implements Deribit's credit based rate limits on the
+// client side.
+//
+// Every request costs credits from one of two buckets: order entry methods
+// draw from the matching engine bucket, everything else from the
+// non-matching engine bucket. Buckets refill continuously up to their
+// capacity. See https://docs.deribit.com/#rate-limits
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+// ErrRateLimited is returned by a fail-fast Limiter when a call would have
+// to wait for credits.
+var ErrRateLimited = errors.New("rate limited")
+
+// Bucket identifies a credit pool.
+type Bucket int
+
+const (
+	NonMatchingEngine Bucket = iota
+	MatchingEngine
+)
+
+func (b Bucket) String() string {
+	switch b {
+	case NonMatchingEngine:
+		return "non_matching_engine"
+	case MatchingEngine:
+		return "matching_engine"
+	}
+	return fmt.Sprintf("Bucket(%d)", int(b))
+}
+
+// BucketConfig sizes a credit pool.
+type BucketConfig struct {
+	// Cost is the credits one request takes.
+	Cost float64 `json:"cost"`
+	// Capacity is the most credits the bucket holds, which bounds bursts.
+	Capacity float64 `json:"capacity"`
+	// Refill is the credits added per second.
+	Refill float64 `json:"refill"`
+}
+
+// Config configures a Limiter.
+type Config struct {
+	NonMatchingEngine BucketConfig `json:"non_matching_engine"`
+	MatchingEngine    BucketConfig `json:"matching_engine"`
+	// FailFast makes Wait return ErrRateLimited instead of waiting.
+	FailFast bool `json:"fail_fast"`
+	// Clock defaults to the system clock.
+	Clock Clock `json:"-"`
+}
+
+// DefaultConfig matches Deribit's default account limits: 20 non-matching
+// requests per second with bursts of 100, and 5 order entry requests per
+// second with bursts of 20.
+func DefaultConfig() Config {
+	return Config{
+		NonMatchingEngine: BucketConfig{Cost: 500, Capacity: 50000, Refill: 10000},
+		MatchingEngine:    BucketConfig{Cost: 1000, Capacity: 20000, Refill: 5000},
+	}
+}
+
+// minRefillFactor bounds how far too_many_requests errors slow a bucket
+// down.
+const minRefillFactor = 0.125
+
+// Limiter hands out credits for API calls. It is safe for concurrent use and
+// may be shared by several clients on the same account, which is how the
+// server counts.
+type Limiter struct {
+	clock    Clock
+	failFast bool
+
+	mu      sync.Mutex
+	buckets [2]*bucket
+}
+
+// New returns a Limiter with full buckets.
+func New(cfg Config) *Limiter {
+	clock := cfg.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	now := clock.Now()
+	return &Limiter{
+		clock:    clock,
+		failFast: cfg.FailFast,
+		buckets: [2]*bucket{
+			NonMatchingEngine: newBucket(cfg.NonMatchingEngine, now),
+			MatchingEngine:    newBucket(cfg.MatchingEngine, now),
+		},
+	}
+}
+
+// Wait takes the credits for a call to method, waiting until the bucket
+// holds enough. Waiting callers are served in order. It returns ctx's error
+// if ctx is done first, and ErrRateLimited if the Limiter fails fast.
+func (l *Limiter) Wait(ctx context.Context, method string) error {
+	b := l.buckets[Classify(method)]
+
+	l.mu.Lock()
+	now := l.clock.Now()
+	b.refill(now)
+	if l.failFast && b.credits < b.cfg.Cost {
+		l.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRateLimited, method)
+	}
+	// Take the credits up front, possibly going negative, so that later
+	// callers queue behind this one.
+	b.credits -= b.cfg.Cost
+	delay := b.delay()
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-l.clock.After(delay):
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.credits += b.cfg.Cost
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Allow takes the credits for a call to method if they are available now.
+func (l *Limiter) Allow(method string) bool {
+	b := l.buckets[Classify(method)]
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b.refill(l.clock.Now())
+	if b.credits < b.cfg.Cost {
+		return false
+	}
+	b.credits -= b.cfg.Cost
+	return true
+}
+
+// Observe adapts the limiter to the outcome of a call to method. A
+// too_many_requests error means the server's view of the bucket is empty:
+// the local bucket is drained and its refill rate halved. Successful calls
+// restore the rate step by step.
+func (l *Limiter) Observe(method string, err error) {
+	b := l.buckets[Classify(method)]
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b.refill(l.clock.Now())
+	if errors.Is(err, deribit.ErrTooManyRequests) {
+		b.credits = math.Min(b.credits, 0)
+		b.factor = math.Max(b.factor/2, minRefillFactor)
+		return
+	}
+	if err == nil && b.factor < 1 {
+		b.factor = math.Min(b.factor*1.1, 1)
+	}
+}
+
+// Remaining returns the credits currently available in bucket. It is
+// negative while callers wait for credits.
+func (l *Limiter) Remaining(bucket Bucket) float64 {
+	b := l.buckets[bucket]
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b.refill(l.clock.Now())
+	return b.credits
+}
+
+// RefillRate returns the credits per second bucket currently refills at,
+// which is below the configured rate after too_many_requests errors.
+func (l *Limiter) RefillRate(bucket Bucket) float64 {
+	b := l.buckets[bucket]
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return b.rate()
+}
+
+type bucket struct {
+	cfg     BucketConfig
+	credits float64
+	factor  float64
+	updated time.Time
+}
+
+func newBucket(cfg BucketConfig, now time.Time) *bucket {
+	return &bucket{cfg: cfg, credits: cfg.Capacity, factor: 1, updated: now}
+}
+
+func (b *bucket) rate() float64 {
+	return b.cfg.Refill * b.factor
+}
+
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	b.updated = now
+	b.credits = math.Min(b.credits+elapsed*b.rate(), b.cfg.Capacity)
+}
+
+// delay is how long until the bucket is back at zero credits.
+func (b *bucket) delay() time.Duration {
+	if b.credits >= 0 {
+		return 0
+	}
+	if b.rate() <= 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(-b.credits / b.rate() * float64(time.Second))
+}
@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xingxing/deribit-api/pkg/deribit"
+)
+
+// fakeClock only moves when Advance is called.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	waiting := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			waiting = append(waiting, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = waiting
+}
+
+// awaitWaiters blocks until n callers wait on the clock.
+func (c *fakeClock) awaitWaiters(t *testing.T, n int) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return len(c.waiters) == n
+	}, time.Second, time.Millisecond)
+}
+
+func newTestLimiter(failFast bool) (*Limiter, *fakeClock) {
+	clock := newFakeClock()
+	cfg := DefaultConfig()
+	cfg.Clock = clock
+	cfg.FailFast = failFast
+	return New(cfg), clock
+}
+
+func TestAllow_Burst(t *testing.T) {
+	l, clock := newTestLimiter(false)
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, l.Allow("public/ticker"), "request %d", i)
+	}
+	assert.False(t, l.Allow("public/ticker"))
+	assert.Equal(t, 0.0, l.Remaining(NonMatchingEngine))
+
+	// Order entry draws from its own bucket.
+	for i := 0; i < 20; i++ {
+		assert.True(t, l.Allow("private/buy"), "order %d", i)
+	}
+	assert.False(t, l.Allow("private/cancel"))
+
+	clock.Advance(50 * time.Millisecond)
+	assert.Equal(t, 500.0, l.Remaining(NonMatchingEngine))
+	assert.True(t, l.Allow("public/ticker"))
+
+	// Buckets do not fill beyond their capacity.
+	clock.Advance(time.Hour)
+	assert.Equal(t, 50000.0, l.Remaining(NonMatchingEngine))
+	assert.Equal(t, 20000.0, l.Remaining(MatchingEngine))
+}
+
+func TestWait_Queues(t *testing.T) {
+	l, clock := newTestLimiter(false)
+	for l.Allow("private/buy") {
+	}
+
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- l.Wait(context.Background(), "private/edit") }()
+	}
+	clock.awaitWaiters(t, 2)
+	assert.Equal(t, -2000.0, l.Remaining(MatchingEngine))
+
+	clock.Advance(199 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Wait returned before the credits refilled")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	assert.NoError(t, <-done)
+	clock.Advance(200 * time.Millisecond)
+	assert.NoError(t, <-done)
+}
+
+func TestWait_CancelRefunds(t *testing.T) {
+	l, clock := newTestLimiter(false)
+	for l.Allow("private/buy") {
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Wait(ctx, "private/buy") }()
+	clock.awaitWaiters(t, 1)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 0.0, l.Remaining(MatchingEngine))
+}
+
+func TestWait_FailFast(t *testing.T) {
+	l, _ := newTestLimiter(true)
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, l.Wait(context.Background(), "private/sell"))
+	}
+	assert.ErrorIs(t, l.Wait(context.Background(), "private/sell"), ErrRateLimited)
+	assert.NoError(t, l.Wait(context.Background(), "public/get_time"))
+}
+
+func TestObserve_TooManyRequests(t *testing.T) {
+	l, clock := newTestLimiter(false)
+
+	l.Observe("private/buy", &deribit.APIError{Code: deribit.CodeTooManyRequests, Message: "too_many_requests"})
+	assert.Equal(t, 0.0, l.Remaining(MatchingEngine))
+	assert.Equal(t, 2500.0, l.RefillRate(MatchingEngine))
+	assert.Equal(t, 10000.0, l.RefillRate(NonMatchingEngine))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 2500.0, l.Remaining(MatchingEngine))
+
+	for i := 0; i < 10; i++ {
+		l.Observe("private/sell", deribit.ErrTooManyRequests)
+	}
+	assert.Equal(t, 5000*minRefillFactor, l.RefillRate(MatchingEngine))
+
+	// Other errors leave the rate alone; successes restore it.
+	l.Observe("private/buy", errors.New("connection reset by peer"))
+	assert.Equal(t, 5000*minRefillFactor, l.RefillRate(MatchingEngine))
+	for i := 0; i < 50; i++ {
+		l.Observe("private/buy", nil)
+	}
+	assert.Equal(t, 5000.0, l.RefillRate(MatchingEngine))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, MatchingEngine, Classify("private/buy"))
+	assert.Equal(t, MatchingEngine, Classify("/private/cancel_all_by_instrument"))
+	assert.Equal(t, NonMatchingEngine, Classify("private/get_open_orders_by_instrument"))
+	assert.Equal(t, NonMatchingEngine, Classify("public/unknown"))
+}
+
+// TestClassify_APIMethods checks that every method the clients call is
+// classified explicitly.
+func TestClassify_APIMethods(t *testing.T) {
+	files, err := filepath.Glob("../../clients/websocket/api_*.go")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	method := regexp.MustCompile(`"((?:public|private)/\w+)"`)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		for _, m := range method.FindAllStringSubmatch(string(src), -1) {
+			_, ok := methods[m[1]]
+			assert.True(t, ok, "%s in %s is not classified", m[1], filepath.Base(file))
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package recorder writes book and trade notifications to compact binary
+// files, one per instrument per UTC day, and reads them back as the same
+// notification types.
+//
+// A file starts with the magic "DRBR", a format version byte and the
+// instrument name. Records follow, each prefixed with its length as a
+// uvarint, so readers skip record kinds they do not know. A record body is
+// a kind byte, the server timestamp in milliseconds and the kind's fields:
+// integers as varints, prices and amounts as little-endian float64 and
+// strings as uvarint length and bytes. Snapshot records hold the full book
+// and are written periodically, so a reader can Seek to any time without
+// replaying the file from the start.
+//
+// The Recorder also keeps an index next to each recording, named by
+// appending IndexExtension. It holds one 16-byte entry per snapshot record:
+// the record's timestamp in milliseconds and its offset in the recording,
+// both as little-endian int64. Seek uses it to find the snapshot without
+// reading the records before it.
+package recorder
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+const (
+	magic = "DRBR"
+	// Version is the format version written by this package.
+	Version = 1
+)
+
+var (
+	// ErrFormat is returned for data that is not a recording.
+	ErrFormat = errors.New("not a recording")
+	// ErrUnsupportedVersion is returned for recordings of a newer format.
+	ErrUnsupportedVersion = errors.New("unsupported recording version")
+)
+
+// Kind is the type of a record.
+type Kind byte
+
+const (
+	// KindSnapshot is a full book, with PrevChangeID 0 and only "new"
+	// items, as sent first on book.{instrument}.raw.
+	KindSnapshot Kind = 1
+	// KindBook is a book.{instrument}.raw notification.
+	KindBook Kind = 2
+	// KindTrades is a trades.{instrument}.{interval} notification.
+	KindTrades Kind = 3
+)
+
+// Record is one recorded notification.
+type Record struct {
+	Kind      Kind
+	Timestamp time.Time
+	// Book is set for KindSnapshot and KindBook.
+	Book *models.OrderBookRawNotification
+	// Trades is set for KindTrades.
+	Trades models.TradesNotification
+}
+
+var actions = []string{"new", "change", "delete"}
+
+func appendHeader(buf []byte, instrument string) []byte {
+	buf = append(buf, magic...)
+	buf = append(buf, Version)
+	return appendString(buf, instrument)
+}
+
+func appendBook(buf []byte, kind Kind, n *models.OrderBookRawNotification) []byte {
+	buf = append(buf, byte(kind))
+	buf = binary.AppendVarint(buf, n.Timestamp)
+	buf = binary.AppendVarint(buf, n.PrevChangeID)
+	buf = binary.AppendVarint(buf, n.ChangeID)
+	buf = appendItems(buf, n.Bids)
+	return appendItems(buf, n.Asks)
+}
+
+func appendItems(buf []byte, items []models.OrderBookNotificationItem) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(items)))
+	for _, item := range items {
+		action := byte(0)
+		for i, a := range actions {
+			if a == item.Action {
+				action = byte(i)
+			}
+		}
+		buf = append(buf, action)
+		buf = appendFloat(buf, item.Price)
+		buf = appendFloat(buf, item.Amount)
+	}
+	return buf
+}
+
+func appendTrades(buf []byte, trades models.TradesNotification) []byte {
+	var timestamp int64
+	if len(trades) > 0 {
+		timestamp = trades[0].Timestamp
+	}
+	buf = append(buf, byte(KindTrades))
+	buf = binary.AppendVarint(buf, timestamp)
+	buf = binary.AppendUvarint(buf, uint64(len(trades)))
+	for _, t := range trades {
+		buf = binary.AppendVarint(buf, int64(t.TradeSeq))
+		buf = appendString(buf, t.TradeID)
+		buf = binary.AppendVarint(buf, t.Timestamp-timestamp)
+		buf = binary.AppendVarint(buf, int64(t.TickDirection))
+		buf = appendFloat(buf, t.Price)
+		buf = appendFloat(buf, t.Iv)
+		buf = appendFloat(buf, t.IndexPrice)
+		buf = appendString(buf, t.Direction)
+		buf = appendFloat(buf, t.Amount)
+	}
+	return buf
+}
+
+func appendFloat(buf []byte, f float64) []byte {
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f))
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// decoder reads the fields of a record body. The first malformed field
+// sets err and makes every later read return zero.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: truncated record", ErrFormat)
+	}
+	d.buf = nil
+}
+
+func (d *decoder) byte() byte {
+	if len(d.buf) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+	return b
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count reads a length, rejecting lengths the rest of the body cannot
+// hold at minSize bytes per element.
+func (d *decoder) count(minSize int) int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)/minSize) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) float() float64 {
+	if len(d.buf) < 8 {
+		d.fail()
+		return 0
+	}
+	f := math.Float64frombits(binary.LittleEndian.Uint64(d.buf))
+	d.buf = d.buf[8:]
+	return f
+}
+
+func (d *decoder) string() string {
+	n := d.count(1)
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+	return s
+}
+
+func (d *decoder) items() []models.OrderBookNotificationItem {
+	items := make([]models.OrderBookNotificationItem, d.count(17))
+	for i := range items {
+		action := int(d.byte())
+		if action >= len(actions) {
+			action = 0
+		}
+		items[i] = models.OrderBookNotificationItem{Action: actions[action], Price: d.float(), Amount: d.float()}
+	}
+	return items
+}
+
+// decodeRecord decodes a record body. ok is false for kinds this version
+// does not know.
+func decodeRecord(body []byte, instrument string) (record Record, ok bool, err error) {
+	d := &decoder{buf: body}
+	record.Kind = Kind(d.byte())
+	timestamp := d.varint()
+	record.Timestamp = time.UnixMilli(timestamp)
+
+	switch record.Kind {
+	case KindSnapshot, KindBook:
+		n := &models.OrderBookRawNotification{InstrumentName: instrument, Timestamp: timestamp}
+		n.PrevChangeID = d.varint()
+		n.ChangeID = d.varint()
+		n.Bids = d.items()
+		n.Asks = d.items()
+		record.Book = n
+	case KindTrades:
+		trades := make(models.TradesNotification, d.count(36))
+		for i := range trades {
+			trades[i] = models.Trade{
+				TradeSeq:       int(d.varint()),
+				TradeID:        d.string(),
+				Timestamp:      timestamp + d.varint(),
+				TickDirection:  int(d.varint()),
+				Price:          d.float(),
+				Iv:             d.float(),
+				IndexPrice:     d.float(),
+				Direction:      d.string(),
+				Amount:         d.float(),
+				InstrumentName: instrument,
+			}
+		}
+		record.Trades = trades
+	default:
+		return Record{}, false, d.err
+	}
+	if d.err != nil {
+		return Record{}, false, d.err
+	}
+	return record, true, nil
+}
@@ -0,0 +1,179 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
+)
+
+func book(prev, change, timestamp int64, bids, asks []models.OrderBookNotificationItem) *models.OrderBookRawNotification {
+	return &models.OrderBookRawNotification{
+		InstrumentName: "BTC-PERPETUAL",
+		Timestamp:      timestamp,
+		PrevChangeID:   prev,
+		ChangeID:       change,
+		Bids:           bids,
+		Asks:           asks,
+	}
+}
+
+func items(action string, levels ...float64) []models.OrderBookNotificationItem {
+	result := []models.OrderBookNotificationItem{}
+	for i := 0; i+1 < len(levels); i += 2 {
+		result = append(result, models.OrderBookNotificationItem{Action: action, Price: levels[i], Amount: levels[i+1]})
+	}
+	return result
+}
+
+func TestWriterReader(t *testing.T) {
+	trades := models.TradesNotification{
+		{TradeSeq: 1, TradeID: "100", Timestamp: 1700000000100, TickDirection: 2, Price: 60000.5, IndexPrice: 60001.25, Direction: "buy", Amount: 10, InstrumentName: "BTC-PERPETUAL"},
+		{TradeSeq: 2, TradeID: "101", Timestamp: 1700000000101, TickDirection: 3, Price: 60000, Iv: 0, IndexPrice: 60001.25, Direction: "sell", Amount: 0.1, InstrumentName: "BTC-PERPETUAL"},
+	}
+	notifications := []*models.OrderBookRawNotification{
+		book(0, 10, 1700000000000, items("new", 60000, 100, 59999.5, 20), items("new", 60000.5, 30)),
+		book(10, 11, 1700000000050, items("change", 60000, 80), items("delete", 60000.5, 0)),
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "BTC-PERPETUAL")
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBook(notifications[0]))
+	require.NoError(t, w.WriteBook(notifications[1]))
+	require.NoError(t, w.WriteTrades(trades))
+	require.NoError(t, w.Flush())
+
+	// Compact compared to the JSON the notifications arrive as.
+	jsonData, _ := json.Marshal([]interface{}{notifications, trades})
+	assert.Less(t, buf.Len(), len(jsonData)/2)
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, "BTC-PERPETUAL", r.Instrument())
+
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, KindSnapshot, record.Kind)
+	assert.Equal(t, time.UnixMilli(1700000000000), record.Timestamp)
+	assert.Equal(t, notifications[0], record.Book)
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, KindBook, record.Kind)
+	assert.Equal(t, notifications[1], record.Book)
+
+	record, err = r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, KindTrades, record.Kind)
+	assert.Equal(t, trades, record.Trades)
+
+	_, err = r.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReader_SkipsUnknownKinds(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, "ETH-PERPETUAL")
+	require.NoError(t, w.write([]byte{99, 1, 2, 3}))
+	require.NoError(t, w.WriteBook(book(0, 1, 5, nil, nil)))
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(&buf)
+	require.NoError(t, err)
+	record, err := r.Next()
+	require.NoError(t, err)
+	assert.Equal(t, KindSnapshot, record.Kind)
+	assert.Equal(t, "ETH-PERPETUAL", record.Book.InstrumentName)
+}
+
+func TestReader_Errors(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("JSON{}")))
+	assert.True(t, errors.Is(err, ErrFormat))
+
+	_, err = NewReader(bytes.NewReader(append([]byte(magic), Version+1, 0)))
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, "BTC-PERPETUAL")
+	require.NoError(t, w.WriteBook(book(0, 1, 5, items("new", 1, 2), nil)))
+	require.NoError(t, w.Flush())
+	data := buf.Bytes()
+
+	r, err := NewReader(bytes.NewReader(data[:len(data)-3]))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// A length that does not match the fields.
+	corrupt := appendHeader(nil, "BTC-PERPETUAL")
+	corrupt = binary.AppendUvarint(corrupt, 3)
+	corrupt = append(corrupt, byte(KindBook), 2, 0xff)
+	r, err = NewReader(bytes.NewReader(corrupt))
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.True(t, errors.Is(err, ErrFormat))
+}
+
+func TestReader_Seek(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, "BTC-PERPETUAL")
+	require.NoError(t, w.WriteBook(book(0, 1, 1000, items("new", 100, 1), nil)))
+	require.NoError(t, w.WriteBook(book(1, 2, 2000, items("new", 99, 1), nil)))
+	require.NoError(t, w.WriteSnapshot(orderbook.Snapshot{
+		Depth:     orderbook.Depth{Bids: []orderbook.Level{{Price: 100, Amount: 1}, {Price: 99, Amount: 1}}},
+		ChangeID:  2,
+		Timestamp: time.UnixMilli(3000),
+	}))
+	require.NoError(t, w.WriteBook(book(2, 3, 4000, items("delete", 99, 0), nil)))
+	require.NoError(t, w.Flush())
+
+	tests := []struct {
+		name     string
+		at       int64
+		changeID int64
+		kind     Kind
+	}{
+		{"before the first snapshot", 500, 1, KindSnapshot},
+		{"after the first snapshot", 2500, 1, KindSnapshot},
+		{"at the periodic snapshot", 3000, 2, KindSnapshot},
+		{"after the end", 9000, 2, KindSnapshot},
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, r.Seek(time.UnixMilli(tt.at)))
+			record, err := r.Next()
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, record.Kind)
+			assert.Equal(t, tt.changeID, record.Book.ChangeID)
+		})
+	}
+
+	// Replaying from a snapshot rebuilds the book.
+	require.NoError(t, r.Seek(time.UnixMilli(4500)))
+	books := orderbook.New(nil, orderbook.Config{})
+	defer books.Close()
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		books.Apply(record.Book)
+	}
+	assert.Equal(t, []orderbook.Level{{Price: 100, Amount: 1}}, books.Book("BTC-PERPETUAL").Bids(0))
+
+	plain, err := NewReader(bytes.NewBuffer(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, ErrNotSeekable, plain.Seek(time.Now()))
+}
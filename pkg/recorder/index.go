@@ -0,0 +1,73 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// IndexExtension is appended to the path of a recording to name its index,
+// such as 2024-01-02.drec.idx.
+const IndexExtension = ".idx"
+
+// indexEntrySize is the size of an index entry: the timestamp of a snapshot
+// record in milliseconds and its offset in the recording, both as
+// little-endian int64.
+const indexEntrySize = 16
+
+// indexEntry locates a snapshot record.
+type indexEntry struct {
+	timestamp int64
+	offset    int64
+}
+
+func appendIndexEntry(buf []byte, e indexEntry) []byte {
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(e.timestamp))
+	return binary.LittleEndian.AppendUint64(buf, uint64(e.offset))
+}
+
+// readIndex decodes the entries of an index. A trailing partial entry, as
+// left by a crash, is ignored.
+func readIndex(r io.Reader) ([]indexEntry, error) {
+	var entries []indexEntry
+	br := bufio.NewReader(r)
+	buf := make([]byte, indexEntrySize)
+	for {
+		if _, err := io.ReadFull(br, buf); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return entries, nil
+			}
+			return nil, err
+		}
+		entries = append(entries, indexEntry{
+			timestamp: int64(binary.LittleEndian.Uint64(buf)),
+			offset:    int64(binary.LittleEndian.Uint64(buf[8:])),
+		})
+	}
+}
+
+// readIndexFile reads the index at path. A missing index has no entries.
+func readIndexFile(path string) ([]indexEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readIndex(f)
+}
+
+// searchIndex returns the offset of the last snapshot at or before
+// timestamp.
+func searchIndex(entries []indexEntry, timestamp int64) (int64, bool) {
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].timestamp > timestamp })
+	if i == 0 {
+		return 0, false
+	}
+	return entries[i-1].offset, true
+}
@@ -0,0 +1,197 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// maxRecordSize bounds the records a Reader accepts, so a corrupt length
+// cannot make it allocate without limit.
+const maxRecordSize = 64 << 20
+
+// ErrNotSeekable is returned by Seek on a Reader over a plain io.Reader.
+var ErrNotSeekable = errors.New("recording is not seekable")
+
+// Reader decodes the records of a recording.
+type Reader struct {
+	r          io.Reader
+	br         *bufio.Reader
+	closer     io.Closer
+	instrument string
+	version    byte
+	// offset is the position of br in r; start is the first record.
+	offset int64
+	start  int64
+	body   []byte
+	// index holds the snapshots listed in the index of the recording.
+	index []indexEntry
+}
+
+// NewReader reads the header of a recording from r. Seek needs r to be an
+// io.Seeker positioned at the start of the recording.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{r: r, br: bufio.NewReader(r)}
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(reader.br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, ErrFormat
+	}
+	reader.version = header[len(magic)]
+	if reader.version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, reader.version)
+	}
+	reader.offset = int64(len(header))
+	instrument, err := reader.readBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	reader.instrument = string(instrument)
+	reader.start = reader.offset
+	return reader, nil
+}
+
+// Open opens the recording at path, along with its index if there is one.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	reader.closer = f
+
+	// The index only speeds up Seek; without it Seek scans the recording.
+	info, err := f.Stat()
+	if err != nil {
+		return reader, nil
+	}
+	entries, err := readIndexFile(path + IndexExtension)
+	if err != nil {
+		return reader, nil
+	}
+	for _, e := range entries {
+		if e.offset >= reader.start && e.offset < info.Size() {
+			reader.index = append(reader.index, e)
+		}
+	}
+	return reader, nil
+}
+
+// Close closes the file of a Reader returned by Open.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// Instrument returns the instrument of the recording.
+func (r *Reader) Instrument() string {
+	return r.instrument
+}
+
+// Next returns the next record. It returns io.EOF at the end of the
+// recording and io.ErrUnexpectedEOF if the last record was cut short, as
+// happens when a recorder is killed.
+func (r *Reader) Next() (Record, error) {
+	for {
+		body, err := r.readBody()
+		if err != nil {
+			return Record{}, err
+		}
+		record, ok, err := decodeRecord(body, r.instrument)
+		if err != nil || ok {
+			return record, err
+		}
+	}
+}
+
+// Seek positions r at the last snapshot at or before t, or at the first
+// record if there is none, so that replaying from there rebuilds the book
+// as of t. With an index, Seek jumps to the last indexed snapshot and scans
+// only the records after it.
+func (r *Reader) Seek(t time.Time) error {
+	seeker, ok := r.r.(io.Seeker)
+	if !ok {
+		return ErrNotSeekable
+	}
+	target := r.start
+	if offset, ok := searchIndex(r.index, t.UnixMilli()); ok {
+		target = offset
+	}
+	if err := r.seek(seeker, target); err != nil {
+		return err
+	}
+
+	for {
+		offset := r.offset
+		body, err := r.readBody()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return err
+		}
+		if len(body) == 0 {
+			continue
+		}
+		timestamp, n := binary.Varint(body[1:])
+		if n <= 0 {
+			continue
+		}
+		if time.UnixMilli(timestamp).After(t) {
+			break
+		}
+		if Kind(body[0]) == KindSnapshot {
+			target = offset
+		}
+	}
+	return r.seek(seeker, target)
+}
+
+func (r *Reader) seek(seeker io.Seeker, offset int64) error {
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.br.Reset(r.r)
+	r.offset = offset
+	return nil
+}
+
+// readBody reads one length-prefixed body. The result is valid until the
+// next call.
+func (r *Reader) readBody() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.br)
+	switch {
+	case err == io.EOF:
+		return nil, err
+	case err != nil:
+		return nil, io.ErrUnexpectedEOF
+	case size > maxRecordSize:
+		return nil, fmt.Errorf("%w: record of %d bytes", ErrFormat, size)
+	}
+	if cap(r.body) < int(size) {
+		r.body = make([]byte, size)
+	}
+	body := r.body[:size]
+	if _, err := io.ReadFull(r.br, body); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	r.offset += int64(uvarintLen(size)) + int64(size)
+	return body, nil
+}
+
+func uvarintLen(v uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], v)
+}
@@ -0,0 +1,409 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xingxing/deribit-api/clients/websocket"
+	"github.com/xingxing/deribit-api/pkg/channels"
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
+)
+
+// Extension is the file extension of recordings.
+const Extension = ".drec"
+
+// Config configures a Recorder.
+type Config struct {
+	// Dir receives one directory per instrument holding one recording per
+	// UTC day, such as Dir/BTC-PERPETUAL/2024-01-02.drec.
+	Dir string
+	// Instruments are the instruments whose book and trades are recorded.
+	Instruments []string
+	// TradesInterval is the interval of the trades channels. Empty means
+	// channels.Interval100ms; raw needs an authenticated connection.
+	TradesInterval channels.Interval
+	// SnapshotInterval is how often a full book is recorded. Zero means
+	// one minute.
+	SnapshotInterval time.Duration
+	// FlushInterval is how often buffered records are written to disk,
+	// bounding what a crash loses. Zero means one second.
+	FlushInterval time.Duration
+	// Logger receives write errors. Nil means the standard logger.
+	Logger *logrus.Logger
+}
+
+// Recorder writes the notifications passed to HandleBook and HandleTrades,
+// or those of the channels subscribed by Start, to the recordings of their
+// instruments. It keeps a local book of each
+// instrument to record the periodic snapshots, and records a snapshot
+// after the book resyncs so that a recording never depends on the
+// notifications missed in a gap.
+type Recorder struct {
+	cfg   Config
+	books *orderbook.Manager
+
+	mu      sync.Mutex
+	streams map[string]*stream
+	subs    []*websocket.Subscription
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// stream is the open recording of one instrument.
+type stream struct {
+	instrument string
+	// resynced is set when the book resyncs and cleared once the
+	// snapshot after it is recorded.
+	resynced atomic.Bool
+
+	mu    sync.Mutex
+	day   string
+	file  *os.File
+	index *os.File
+	w     *Writer
+}
+
+// New returns a Recorder that resyncs books from src and starts recording
+// periodic snapshots.
+func New(src orderbook.Source, cfg Config) *Recorder {
+	if cfg.TradesInterval == "" {
+		cfg.TradesInterval = channels.Interval100ms
+	}
+	if cfg.SnapshotInterval <= 0 {
+		cfg.SnapshotInterval = time.Minute
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = logrus.StandardLogger()
+	}
+	r := &Recorder{
+		cfg:     cfg,
+		streams: make(map[string]*stream),
+		done:    make(chan struct{}),
+	}
+	r.books = orderbook.New(src, orderbook.Config{
+		OnResync: func(instrument string, err error) {
+			r.cfg.Logger.Warnf("recorder: resyncing %s: %v", instrument, err)
+			r.stream(instrument).resynced.Store(true)
+		},
+	})
+	r.wg.Add(1)
+	go r.snapshots()
+	return r
+}
+
+// Channels returns the channels Start subscribes to for cfg.Instruments.
+func (r *Recorder) Channels() []string {
+	var names []string
+	for _, instrument := range r.cfg.Instruments {
+		names = append(names,
+			channels.Book(instrument, "", 0, channels.IntervalRaw).String(),
+			channels.Trades(instrument, r.cfg.TradesInterval).String())
+	}
+	return names
+}
+
+// Start subscribes client to the channels of cfg.Instruments and records
+// their notifications until Close. book.{instrument}.raw needs an
+// authenticated client.
+func (r *Recorder) Start(client *websocket.DeribitWSClient) error {
+	var subs []*websocket.Subscription
+	for _, instrument := range r.cfg.Instruments {
+		sub, err := client.OnBookRaw(instrument, r.HandleBook)
+		if err == nil {
+			subs = append(subs, sub)
+			sub, err = client.OnTrades(instrument, r.cfg.TradesInterval, r.HandleTrades)
+		}
+		if err != nil {
+			for _, sub := range subs {
+				_ = sub.Close()
+			}
+			return fmt.Errorf("recorder: subscribing to %s: %w", instrument, err)
+		}
+		subs = append(subs, sub)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, subs...)
+	return nil
+}
+
+// HandleBook records a book.{instrument}.raw notification.
+func (r *Recorder) HandleBook(n *models.OrderBookRawNotification) {
+	s := r.stream(n.InstrumentName)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rotated, err := r.open(s, n.Timestamp)
+	if err != nil {
+		r.cfg.Logger.Errorf("recorder: %v", err)
+		return
+	}
+	book := r.books.Book(n.InstrumentName)
+	// A new file starts with the book as it was before this notification.
+	if rotated && book != nil && book.Synced() && n.PrevChangeID != 0 {
+		r.check(s.w.WriteSnapshot(book.Snapshot(0)))
+	}
+	r.check(s.w.WriteBook(n))
+
+	r.books.Apply(n)
+	book = r.books.Book(n.InstrumentName)
+	if s.resynced.Load() && book.Synced() {
+		r.check(s.w.WriteSnapshot(book.Snapshot(0)))
+		s.resynced.Store(false)
+	}
+}
+
+// HandleTrades records a trades.{instrument}.{interval} notification.
+func (r *Recorder) HandleTrades(n *models.TradesNotification) {
+	byInstrument := make(map[string]models.TradesNotification)
+	for _, trade := range *n {
+		byInstrument[trade.InstrumentName] = append(byInstrument[trade.InstrumentName], trade)
+	}
+	for instrument, trades := range byInstrument {
+		s := r.stream(instrument)
+		s.mu.Lock()
+		if _, err := r.open(s, trades[0].Timestamp); err != nil {
+			r.cfg.Logger.Errorf("recorder: %v", err)
+		} else {
+			r.check(s.w.WriteTrades(trades))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Close stops recording, closes the subscriptions made by Start and closes
+// the recordings.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	subs := r.subs
+	r.subs = nil
+	r.mu.Unlock()
+	var errs []error
+	for _, sub := range subs {
+		errs = append(errs, sub.Close())
+	}
+
+	r.closeOnce.Do(func() { close(r.done) })
+	r.wg.Wait()
+	r.books.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.streams {
+		s.mu.Lock()
+		errs = append(errs, s.close())
+		s.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Recorder) stream(instrument string) *stream {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.streams[instrument]
+	if !ok {
+		s = &stream{instrument: instrument}
+		r.streams[instrument] = s
+	}
+	return s
+}
+
+// snapshots records the book of every instrument each SnapshotInterval
+// and flushes the recordings each FlushInterval.
+func (r *Recorder) snapshots() {
+	defer r.wg.Done()
+	snapshot := time.NewTicker(r.cfg.SnapshotInterval)
+	defer snapshot.Stop()
+	flush := time.NewTicker(r.cfg.FlushInterval)
+	defer flush.Stop()
+	for {
+		withSnapshot := false
+		select {
+		case <-r.done:
+			return
+		case <-snapshot.C:
+			withSnapshot = true
+		case <-flush.C:
+		}
+
+		r.mu.Lock()
+		streams := make([]*stream, 0, len(r.streams))
+		for _, s := range r.streams {
+			streams = append(streams, s)
+		}
+		r.mu.Unlock()
+
+		for _, s := range streams {
+			s.mu.Lock()
+			if book := r.books.Book(s.instrument); withSnapshot && s.w != nil && book != nil && book.Synced() {
+				r.check(s.w.WriteSnapshot(book.Snapshot(0)))
+			}
+			if s.w != nil {
+				r.check(s.w.Flush())
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// open makes sure s writes to the recording of the day of timestamp and
+// reports whether it switched files. Rotation only moves forward: a late
+// record from an earlier day goes to the current recording. Callers hold
+// s.mu.
+func (r *Recorder) open(s *stream, timestamp int64) (bool, error) {
+	day := time.UnixMilli(timestamp).UTC().Format("2006-01-02")
+	if s.w != nil && day <= s.day {
+		return false, nil
+	}
+	if err := s.close(); err != nil {
+		r.cfg.Logger.Errorf("recorder: %v", err)
+	}
+
+	path := filepath.Join(r.cfg.Dir, s.instrument, day+Extension)
+	if err := s.openRecording(path); err != nil {
+		return false, err
+	}
+	s.day = day
+	return true, nil
+}
+
+func (r *Recorder) check(err error) {
+	if err != nil {
+		r.cfg.Logger.Errorf("recorder: %v", err)
+	}
+}
+
+// close flushes and closes the recording of s and its index. Callers hold
+// s.mu.
+func (s *stream) close() error {
+	if s.w == nil {
+		return nil
+	}
+	err := errors.Join(s.w.Flush(), s.file.Close(), s.index.Close())
+	s.file, s.index, s.w = nil, nil, nil
+	return err
+}
+
+// openRecording opens the recording at path and its index for appending,
+// creating them if needed. Callers hold s.mu.
+func (s *stream) openRecording(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	index, w, err := resume(file, path, s.instrument)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	s.file, s.index, s.w = file, index, w
+	return nil
+}
+
+// resume prepares the recording in file for appending and rewrites its
+// index. A record cut short by an earlier crash is truncated away. Only the
+// records after the last indexed snapshot are scanned, so reopening a
+// recording does not read all of it.
+func resume(file *os.File, path string, instrument string) (*os.File, *Writer, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	var w *Writer
+	var entries []indexEntry
+	if info.Size() == 0 {
+		if w, err = NewWriter(file, instrument); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		var end int64
+		if end, entries, err = scanRecording(file, path, info.Size(), instrument); err != nil {
+			return nil, nil, err
+		}
+		if err := file.Truncate(end); err != nil {
+			return nil, nil, err
+		}
+		if _, err := file.Seek(end, io.SeekStart); err != nil {
+			return nil, nil, err
+		}
+		w = newWriter(file, end)
+	}
+
+	index, err := os.OpenFile(path+IndexExtension, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, nil, err
+	}
+	w.index = bufio.NewWriter(index)
+	for _, e := range entries {
+		w.entry = appendIndexEntry(w.entry[:0], e)
+		_, _ = w.index.Write(w.entry)
+	}
+	if err := w.index.Flush(); err != nil {
+		_ = index.Close()
+		return nil, nil, err
+	}
+	return index, w, nil
+}
+
+// scanRecording returns the length of the complete records of the
+// recording in file, which is size bytes long, and the index entries of its
+// snapshots. Entries are taken from the index at path up to its last
+// snapshot, which is where the scan starts. Anything after the last
+// complete record is treated as cut short.
+func scanRecording(file *os.File, path string, size int64, instrument string) (int64, []indexEntry, error) {
+	reader, err := NewReader(file)
+	if err != nil {
+		return 0, nil, err
+	}
+	if reader.Instrument() != instrument {
+		return 0, nil, fmt.Errorf("%w: recording of %s", ErrFormat, reader.Instrument())
+	}
+
+	indexed, err := readIndexFile(path + IndexExtension)
+	if err != nil {
+		return 0, nil, err
+	}
+	var entries []indexEntry
+	for _, e := range indexed {
+		if e.offset >= reader.start && e.offset < size {
+			entries = append(entries, e)
+		}
+	}
+	if n := len(entries); n > 0 {
+		// Rescan from the last indexed snapshot, which is found again.
+		if err := reader.seek(file, entries[n-1].offset); err != nil {
+			return 0, nil, err
+		}
+		entries = entries[:n-1]
+	}
+	for {
+		offset := reader.offset
+		body, err := reader.readBody()
+		if err != nil {
+			return offset, entries, nil
+		}
+		if len(body) > 0 && Kind(body[0]) == KindSnapshot {
+			timestamp, _ := binary.Varint(body[1:])
+			entries = append(entries, indexEntry{timestamp: timestamp, offset: offset})
+		}
+	}
+}
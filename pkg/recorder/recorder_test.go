@@ -0,0 +1,250 @@
+package recorder
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xingxing/deribit-api/pkg/models"
+)
+
+// day1 and day2 are timestamps on consecutive UTC days.
+const (
+	day1 = int64(1700006400000) // 2023-11-15 00:00:00
+	day2 = day1 + 24*60*60*1000
+)
+
+type fakeSource struct {
+	book models.GetOrderBookResponse
+}
+
+func (s *fakeSource) GetOrderBookContext(ctx context.Context, params *models.GetOrderBookParams) (models.GetOrderBookResponse, error) {
+	return s.book, nil
+}
+
+func readAll(t *testing.T, path string) []Record {
+	t.Helper()
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	var records []Record
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func kinds(records []Record) []Kind {
+	var result []Kind
+	for _, record := range records {
+		result = append(result, record.Kind)
+	}
+	return result
+}
+
+// scanIndex lists the snapshots of the recording at path by reading every
+// record.
+func scanIndex(t *testing.T, path string) []indexEntry {
+	t.Helper()
+	r, err := Open(path)
+	require.NoError(t, err)
+	defer r.Close()
+	var entries []indexEntry
+	for {
+		offset := r.offset
+		record, err := r.Next()
+		if err == io.EOF {
+			return entries
+		}
+		require.NoError(t, err)
+		if record.Kind == KindSnapshot {
+			entries = append(entries, indexEntry{timestamp: record.Timestamp.UnixMilli(), offset: offset})
+		}
+	}
+}
+
+func newTestRecorder(t *testing.T, src *fakeSource, interval time.Duration) (*Recorder, string, *logtest.Hook) {
+	dir := t.TempDir()
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	r := New(src, Config{
+		Dir:              dir,
+		Instruments:      []string{"BTC-PERPETUAL"},
+		SnapshotInterval: interval,
+		Logger:           logger,
+	})
+	return r, dir, hook
+}
+
+func TestRecorder_Channels(t *testing.T) {
+	r := New(&fakeSource{}, Config{Instruments: []string{"BTC-PERPETUAL", "ETH-PERPETUAL"}})
+	defer r.Close()
+	assert.Equal(t, []string{
+		"book.BTC-PERPETUAL.raw", "trades.BTC-PERPETUAL.100ms",
+		"book.ETH-PERPETUAL.raw", "trades.ETH-PERPETUAL.100ms",
+	}, r.Channels())
+}
+
+func TestRecorder_Files(t *testing.T) {
+	r, dir, hook := newTestRecorder(t, &fakeSource{}, time.Hour)
+
+	r.HandleBook(book(0, 1, day1+1000, items("new", 100, 1), items("new", 101, 1)))
+	r.HandleBook(book(1, 2, day1+2000, items("new", 99, 2), nil))
+	r.HandleTrades(&models.TradesNotification{
+		{TradeID: "1", InstrumentName: "BTC-PERPETUAL", Timestamp: day1 + 2500, Price: 101, Amount: 1, Direction: "buy"},
+		{TradeID: "2", InstrumentName: "ETH-PERPETUAL", Timestamp: day1 + 2500, Price: 3000, Amount: 1, Direction: "sell"},
+	})
+	// The next day starts a new file with a snapshot of the book.
+	r.HandleBook(book(2, 3, day2+1000, nil, items("delete", 101, 0)))
+	require.NoError(t, r.Close())
+	assert.Empty(t, hook.AllEntries())
+
+	first := readAll(t, filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec"))
+	assert.Equal(t, []Kind{KindSnapshot, KindBook, KindTrades}, kinds(first))
+	assert.Equal(t, "1", first[2].Trades[0].TradeID)
+
+	second := readAll(t, filepath.Join(dir, "BTC-PERPETUAL", "2023-11-16.drec"))
+	if assert.Equal(t, []Kind{KindSnapshot, KindBook}, kinds(second)) {
+		assert.Equal(t, int64(2), second[0].Book.ChangeID)
+		assert.Len(t, second[0].Book.Bids, 2)
+	}
+
+	eth := readAll(t, filepath.Join(dir, "ETH-PERPETUAL", "2023-11-15.drec"))
+	if assert.Equal(t, []Kind{KindTrades}, kinds(eth)) {
+		assert.Equal(t, 3000.0, eth[0].Trades[0].Price)
+	}
+}
+
+func TestRecorder_LateRecords(t *testing.T) {
+	r, dir, hook := newTestRecorder(t, &fakeSource{}, time.Hour)
+
+	r.HandleBook(book(0, 1, day2-1000, items("new", 100, 1), nil))
+	r.HandleBook(book(1, 2, day2+1000, items("new", 99, 1), nil))
+	// Trades from before midnight arrive after the book rotated.
+	r.HandleTrades(&models.TradesNotification{
+		{TradeID: "1", InstrumentName: "BTC-PERPETUAL", Timestamp: day2 - 500, Price: 100, Amount: 1, Direction: "buy"},
+	})
+	require.NoError(t, r.Close())
+	assert.Empty(t, hook.AllEntries())
+
+	first := readAll(t, filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec"))
+	assert.Equal(t, []Kind{KindSnapshot}, kinds(first))
+	second := readAll(t, filepath.Join(dir, "BTC-PERPETUAL", "2023-11-16.drec"))
+	assert.Equal(t, []Kind{KindSnapshot, KindBook, KindTrades}, kinds(second))
+}
+
+func TestRecorder_AppendsAfterCrash(t *testing.T) {
+	r, dir, _ := newTestRecorder(t, &fakeSource{}, time.Hour)
+	r.HandleBook(book(0, 1, day1, items("new", 100, 1), nil))
+	require.NoError(t, r.Close())
+
+	// Simulate a record cut short by a crash.
+	path := filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{40, byte(KindBook), 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r = New(&fakeSource{}, Config{Dir: dir})
+	r.HandleBook(book(0, 5, day1+1000, items("new", 101, 1), nil))
+	require.NoError(t, r.Close())
+
+	records := readAll(t, path)
+	if assert.Equal(t, []Kind{KindSnapshot, KindSnapshot}, kinds(records)) {
+		assert.Equal(t, int64(5), records[1].Book.ChangeID)
+	}
+	index, err := readIndexFile(path + IndexExtension)
+	require.NoError(t, err)
+	assert.Equal(t, scanIndex(t, path), index)
+}
+
+func TestRecorder_Index(t *testing.T) {
+	r, dir, _ := newTestRecorder(t, &fakeSource{}, time.Hour)
+	r.HandleBook(book(0, 1, day1, items("new", 100, 1), nil))
+	r.HandleBook(book(1, 2, day1+1000, items("new", 99, 1), nil))
+	require.NoError(t, r.Close())
+
+	// Reopening appends and keeps indexing, also after the index entry of
+	// a snapshot lost in a crash.
+	path := filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec")
+	f, err := os.OpenFile(path+IndexExtension, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.Write(appendIndexEntry(nil, indexEntry{timestamp: day1 + 1500, offset: 1 << 20}))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r = New(&fakeSource{}, Config{Dir: dir})
+	r.HandleBook(book(0, 3, day1+2000, items("new", 101, 1), nil))
+	r.HandleBook(book(3, 4, day1+3000, items("new", 102, 1), nil))
+	require.NoError(t, r.Close())
+
+	want := scanIndex(t, path)
+	require.Len(t, want, 2)
+	index, err := readIndexFile(path + IndexExtension)
+	require.NoError(t, err)
+	assert.Equal(t, want, index)
+
+	reader, err := Open(path)
+	require.NoError(t, err)
+	defer reader.Close()
+	assert.Equal(t, want, reader.index)
+	require.NoError(t, reader.Seek(time.UnixMilli(day1+2500)))
+	record, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), record.Book.ChangeID)
+}
+
+func TestRecorder_Flush(t *testing.T) {
+	dir := t.TempDir()
+	r := New(&fakeSource{}, Config{Dir: dir, SnapshotInterval: time.Hour, FlushInterval: 10 * time.Millisecond})
+	defer r.Close()
+	r.HandleBook(book(0, 1, day1, items("new", 100, 1), nil))
+
+	// The record reaches the file while the recorder keeps running.
+	path := filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec")
+	assert.Eventually(t, func() bool {
+		reader, err := Open(path)
+		if err != nil {
+			return false
+		}
+		defer reader.Close()
+		record, err := reader.Next()
+		return err == nil && record.Kind == KindSnapshot
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRecorder_Snapshots(t *testing.T) {
+	src := &fakeSource{book: models.GetOrderBookResponse{ChangeID: 3, Bids: [][]float64{{100, 5}}}}
+	r, dir, _ := newTestRecorder(t, src, 20*time.Millisecond)
+
+	r.HandleBook(book(0, 1, day1, items("new", 100, 1), nil))
+	// Change 2 is lost; the book resyncs from the source.
+	r.HandleBook(book(2, 3, day1+1000, items("change", 100, 5), nil))
+	require.Eventually(t, func() bool {
+		b := r.books.Book("BTC-PERPETUAL")
+		return b.Synced() && b.ChangeID() == 3
+	}, 2*time.Second, 5*time.Millisecond)
+	r.HandleBook(book(3, 4, day1+2000, items("new", 99, 1), nil))
+	// Wait for a periodic snapshot.
+	time.Sleep(60 * time.Millisecond)
+	require.NoError(t, r.Close())
+
+	records := readAll(t, filepath.Join(dir, "BTC-PERPETUAL", "2023-11-15.drec"))
+	require.GreaterOrEqual(t, len(records), 5)
+	assert.Equal(t, []Kind{KindSnapshot, KindBook, KindBook, KindSnapshot, KindSnapshot}, kinds(records)[:5])
+	// The snapshot after the resync continues from change 4.
+	assert.Equal(t, int64(4), records[3].Book.ChangeID)
+	assert.Len(t, records[3].Book.Bids, 2)
+}
@@ -0,0 +1,103 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/xingxing/deribit-api/pkg/models"
+	"github.com/xingxing/deribit-api/pkg/orderbook"
+)
+
+// Writer encodes records to a recording. It is not safe for concurrent
+// use.
+type Writer struct {
+	w *bufio.Writer
+	// index, if set, receives an entry for every snapshot record; offset
+	// is the position of the next record.
+	index  *bufio.Writer
+	offset int64
+	buf    []byte
+	body   []byte
+	entry  []byte
+}
+
+// NewWriter starts a recording of instrument on w.
+func NewWriter(w io.Writer, instrument string) (*Writer, error) {
+	header := appendHeader(nil, instrument)
+	writer := newWriter(w, 0)
+	if _, err := writer.w.Write(header); err != nil {
+		return nil, err
+	}
+	writer.offset = int64(len(header))
+	return writer, nil
+}
+
+// newWriter continues a recording at offset, after its header.
+func newWriter(w io.Writer, offset int64) *Writer {
+	return &Writer{w: bufio.NewWriter(w), offset: offset}
+}
+
+// WriteBook records a book.{instrument}.raw notification. The first
+// notification of a subscription is recorded as a snapshot.
+func (w *Writer) WriteBook(n *models.OrderBookRawNotification) error {
+	kind := KindBook
+	if n.PrevChangeID == 0 {
+		kind = KindSnapshot
+	}
+	return w.write(appendBook(w.body[:0], kind, n))
+}
+
+// WriteSnapshot records the full book s.
+func (w *Writer) WriteSnapshot(s orderbook.Snapshot) error {
+	n := &models.OrderBookRawNotification{
+		Timestamp: s.Timestamp.UnixMilli(),
+		ChangeID:  s.ChangeID,
+		Bids:      newItems(s.Bids),
+		Asks:      newItems(s.Asks),
+	}
+	return w.write(appendBook(w.body[:0], KindSnapshot, n))
+}
+
+// WriteTrades records a trades.{instrument}.{interval} notification.
+func (w *Writer) WriteTrades(trades models.TradesNotification) error {
+	return w.write(appendTrades(w.body[:0], trades))
+}
+
+// Flush writes buffered records, then their index entries, to the
+// underlying writers.
+func (w *Writer) Flush() error {
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.index != nil {
+		return w.index.Flush()
+	}
+	return nil
+}
+
+func (w *Writer) write(body []byte) error {
+	w.body = body
+	w.buf = binary.AppendUvarint(w.buf[:0], uint64(len(body)))
+	w.buf = append(w.buf, body...)
+	if _, err := w.w.Write(w.buf); err != nil {
+		return err
+	}
+	offset := w.offset
+	w.offset += int64(len(w.buf))
+	if w.index == nil || Kind(body[0]) != KindSnapshot {
+		return nil
+	}
+	timestamp, _ := binary.Varint(body[1:])
+	w.entry = appendIndexEntry(w.entry[:0], indexEntry{timestamp: timestamp, offset: offset})
+	_, err := w.index.Write(w.entry)
+	return err
+}
+
+func newItems(levels []orderbook.Level) []models.OrderBookNotificationItem {
+	items := make([]models.OrderBookNotificationItem, len(levels))
+	for i, level := range levels {
+		items[i] = models.OrderBookNotificationItem{Action: "new", Price: level.Price, Amount: level.Amount}
+	}
+	return items
+}